@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/npu_allocator"
+)
+
+const (
+	opAllocate = "allocate"
+	opRelease  = "release"
+)
+
+// event is a line of the trace, such as {"op": "allocate", "id": "pod-1", "size": 2} or {"op": "release", "id": "pod-1"}.
+type event struct {
+	Op   string `json:"op"`
+	ID   string `json:"id"`
+	Size int    `json:"size,omitempty"`
+}
+
+type allocatorFactory func(topology npu_allocator.MockTopology) (npu_allocator.NpuAllocator, error)
+
+var allocatorFactories = map[string]allocatorFactory{
+	"bin-packing": func(topology npu_allocator.MockTopology) (npu_allocator.NpuAllocator, error) {
+		return npu_allocator.NewMockBinPackingNpuAllocator(topology.TopologyHintMatrix)
+	},
+	"score-based": func(topology npu_allocator.MockTopology) (npu_allocator.NpuAllocator, error) {
+		return npu_allocator.NewMockScoreBasedOptimalNpuAllocator(topology.TopologyHintProvider())
+	},
+	"spread": func(topology npu_allocator.MockTopology) (npu_allocator.NpuAllocator, error) {
+		return npu_allocator.NewMockSpreadNpuAllocator(topology.TopologyHintProvider())
+	},
+}
+
+type metrics struct {
+	allocator     string
+	requests      int
+	failed        int
+	totalScore    uint
+	fragmentation []float64
+	latencies     []time.Duration
+}
+
+func (m *metrics) averageScore() float64 {
+	succeeded := m.requests - m.failed
+	if succeeded == 0 {
+		return 0
+	}
+
+	return float64(m.totalScore) / float64(succeeded)
+}
+
+func (m *metrics) averageFragmentation() float64 {
+	if len(m.fragmentation) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, f := range m.fragmentation {
+		total += f
+	}
+
+	return total / float64(len(m.fragmentation))
+}
+
+func (m *metrics) maxFragmentation() float64 {
+	result := 0.0
+	for _, f := range m.fragmentation {
+		result = max(result, f)
+	}
+
+	return result
+}
+
+func (m *metrics) latencyPercentile(percentile float64) time.Duration {
+	if len(m.latencies) == 0 {
+		return 0
+	}
+
+	sorted := append([]time.Duration(nil), m.latencies...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	// nearest-rank method: the smallest latency which is greater than or equal to `percentile` of latencies.
+	idx := int(math.Ceil(percentile*float64(len(sorted)))) - 1
+
+	return sorted[max(idx, 0)]
+}
+
+func (m *metrics) averageLatency() time.Duration {
+	if len(m.latencies) == 0 {
+		return 0
+	}
+
+	total := time.Duration(0)
+	for _, latency := range m.latencies {
+		total += latency
+	}
+
+	return total / time.Duration(len(m.latencies))
+}
+
+func main() {
+	topologyPath := flag.String("topology", "", "path of the topology hint matrix file in JSON or YAML")
+	tracePath := flag.String("trace", "", "path of the allocate/release trace in JSONL")
+	partitions := flag.Int("partitions", 1, "number of devices per card")
+	allocators := flag.String("allocators", "bin-packing,score-based", "comma separated allocators to compare: bin-packing, score-based, spread")
+	timelinePath := flag.String("timeline", "", "optional path to write fragmentation index after each event in CSV")
+	flag.Parse()
+
+	if *topologyPath == "" || *tracePath == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	topology, err := buildTopology(*topologyPath, *partitions)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	events, err := readTrace(*tracePath)
+	if err != nil {
+		fmt.Printf("%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	results := make([]*metrics, 0)
+	for _, name := range strings.Split(*allocators, ",") {
+		name = strings.TrimSpace(name)
+
+		factory, ok := allocatorFactories[name]
+		if !ok {
+			fmt.Printf("unknown allocator %q\n", name)
+			os.Exit(1)
+		}
+
+		allocator, err := factory(topology)
+		if err != nil {
+			fmt.Printf("%s\n", err.Error())
+			os.Exit(1)
+		}
+
+		result, err := replay(name, allocator, topology, events)
+		if err != nil {
+			fmt.Printf("%s\n", err.Error())
+			os.Exit(1)
+		}
+
+		results = append(results, result)
+	}
+
+	report(results)
+
+	if *timelinePath != "" {
+		if err = writeTimeline(*timelinePath, results); err != nil {
+			fmt.Printf("%s\n", err.Error())
+			os.Exit(1)
+		}
+	}
+}
+
+// buildTopology creates `partitions` mock devices for each card of the topology hint matrix.
+func buildTopology(path string, partitions int) (npu_allocator.MockTopology, error) {
+	topologyHintMatrix, err := npu_allocator.LoadTopologyHintMatrix(path)
+	if err != nil {
+		return npu_allocator.MockTopology{}, err
+	}
+
+	if partitions < 1 {
+		return npu_allocator.MockTopology{}, fmt.Errorf("number of partitions must be positive, but got %d", partitions)
+	}
+
+	devices := npu_allocator.NewDeviceSet()
+	for index, key := range topologyHintMatrix.Keys() {
+		for i := 0; i < partitions; i++ {
+			devices.Insert(npu_allocator.NewMockDevice(index, fmt.Sprintf("%s_%d", key, i), key))
+		}
+	}
+
+	return npu_allocator.MockTopology{
+		Name:               path,
+		TopologyHintMatrix: topologyHintMatrix,
+		Devices:            devices,
+	}, nil
+}
+
+func readTrace(path string) ([]event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	events := make([]event, 0)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var e event
+		if err = json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("couldn't parse line %d of the trace: %w", line, err)
+		}
+
+		if e.Op != opAllocate && e.Op != opRelease {
+			return nil, fmt.Errorf("unknown op %q at line %d of the trace", e.Op, line)
+		}
+
+		events = append(events, e)
+	}
+
+	return events, scanner.Err()
+}
+
+// replay runs events against the allocator. Releasing an unknown or failed allocation is ignored.
+func replay(name string, allocator npu_allocator.NpuAllocator, topology npu_allocator.MockTopology, events []event) (*metrics, error) {
+	planner, err := npu_allocator.NewMockCapacityPlanner(topology.TopologyHintMatrix)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &metrics{allocator: name}
+	available := npu_allocator.NewDeviceSet(topology.Devices.Devices()...)
+	allocated := make(map[string]npu_allocator.DeviceSet)
+
+	for _, e := range events {
+		switch e.Op {
+		case opAllocate:
+			if _, ok := allocated[e.ID]; ok {
+				return nil, fmt.Errorf("%q is allocated twice without release", e.ID)
+			}
+
+			result.requests++
+
+			start := time.Now()
+			devices := allocator.Allocate(available, npu_allocator.NewDeviceSet(), e.Size)
+			result.latencies = append(result.latencies, time.Since(start))
+
+			if devices == nil || devices.Len() != e.Size || !available.Contains(devices.Devices()...) {
+				result.failed++
+				break
+			}
+
+			result.totalScore += planner.Score(devices)
+			allocated[e.ID] = devices
+			available = available.Difference(devices.Devices()...)
+
+		case opRelease:
+			if devices, ok := allocated[e.ID]; ok {
+				available = available.Union(devices.Devices()...)
+				delete(allocated, e.ID)
+			}
+		}
+
+		result.fragmentation = append(result.fragmentation, planner.FragmentationIndex(available))
+	}
+
+	return result, nil
+}
+
+func report(results []*metrics) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "ALLOCATOR\tREQUESTS\tFAILED\tAVG SCORE\tAVG FRAGMENTATION\tMAX FRAGMENTATION\tAVG LATENCY\tP99 LATENCY")
+	for _, m := range results {
+		_, _ = fmt.Fprintf(writer, "%s\t%d\t%d\t%.2f\t%.3f\t%.3f\t%s\t%s\n",
+			m.allocator, m.requests, m.failed, m.averageScore(), m.averageFragmentation(), m.maxFragmentation(),
+			m.averageLatency(), m.latencyPercentile(0.99))
+	}
+
+	_ = writer.Flush()
+}
+
+func writeTimeline(path string, results []*metrics) error {
+	var builder strings.Builder
+	builder.WriteString("step")
+	for _, m := range results {
+		builder.WriteString("," + m.allocator)
+	}
+
+	builder.WriteString("\n")
+
+	if len(results) > 0 {
+		for step := range results[0].fragmentation {
+			builder.WriteString(fmt.Sprintf("%d", step+1))
+			for _, m := range results {
+				builder.WriteString(fmt.Sprintf(",%.3f", m.fragmentation[step]))
+			}
+
+			builder.WriteString("\n")
+		}
+	}
+
+	return os.WriteFile(path, []byte(builder.String()), 0644)
+}
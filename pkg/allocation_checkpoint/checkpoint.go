@@ -0,0 +1,150 @@
+package allocation_checkpoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	DefaultCheckpointDir      = "/var/lib/furiosa"
+	DefaultCheckpointFileName = "allocation_checkpoint.json"
+	DefaultPermissions        = 0644
+	checkpointVersion         = "v1"
+)
+
+// ErrChecksumMismatch is returned when the checkpoint file is corrupted or modified by hand.
+var ErrChecksumMismatch = errors.New("checksum of allocation checkpoint mismatched")
+
+type checkpointFile struct {
+	Version  string           `json:"version"`
+	Data     *AllocationState `json:"data"`
+	Checksum string           `json:"checksum"`
+}
+
+// calculateChecksum returns hex encoded sha256 checksum of the given state.
+// json.Marshal sorts map keys, so the checksum is stable for the same state.
+func calculateChecksum(state *AllocationState) (string, error) {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(raw)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// WriteCheckpoint writes the given state to the path atomically.
+// The state is written to a temporary file under the same directory, synced, and renamed to the path,
+// so the reader never observes partially written checkpoint.
+func WriteCheckpoint(path string, state *AllocationState) error {
+	if state == nil || state.Allocations == nil {
+		state = NewAllocationState()
+	}
+
+	checksum, err := calculateChecksum(state)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.MarshalIndent(&checkpointFile{
+		Version:  checkpointVersion,
+		Data:     state,
+		Checksum: checksum,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := tmpFile.Name()
+	// remove the temporary file if anything goes wrong before rename.
+	defer func() {
+		_ = os.Remove(tmpPath)
+	}()
+
+	if _, err = tmpFile.Write(raw); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+
+	if err = tmpFile.Sync(); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Chmod(tmpPath, DefaultPermissions); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	// sync the parent directory to persist the rename.
+	dirFile, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = dirFile.Close()
+	}()
+
+	return dirFile.Sync()
+}
+
+// ReadCheckpoint reads the state from the path and verifies its checksum.
+// If the file does not exist, the returned error satisfies errors.Is(err, os.ErrNotExist).
+func ReadCheckpoint(path string) (*AllocationState, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoint checkpointFile
+	if err = json.Unmarshal(raw, &checkpoint); err != nil {
+		return nil, fmt.Errorf("couldn't parse allocation checkpoint %s: %w", path, err)
+	}
+
+	if checkpoint.Version != checkpointVersion {
+		return nil, fmt.Errorf("unsupported allocation checkpoint version %q", checkpoint.Version)
+	}
+
+	state := checkpoint.Data
+	if state == nil {
+		state = NewAllocationState()
+	}
+
+	if state.Allocations == nil {
+		state.Allocations = make(map[string][]AllocatedDevice)
+	}
+
+	checksum, err := calculateChecksum(state)
+	if err != nil {
+		return nil, err
+	}
+
+	if checksum != checkpoint.Checksum {
+		return nil, fmt.Errorf("%w: %s", ErrChecksumMismatch, path)
+	}
+
+	return state, nil
+}
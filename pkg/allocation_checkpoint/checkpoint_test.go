@@ -0,0 +1,102 @@
+package allocation_checkpoint
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	devices, err := furiosa_device.NewFuriosaDevices(smi.GetStaticMockDevices(smi.ArchRngd), nil, furiosa_device.QuadCorePolicy)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		description string
+		state       *AllocationState
+		expected    *AllocationState
+	}{
+		{
+			description: "empty state",
+			state:       NewAllocationState(),
+			expected:    NewAllocationState(),
+		},
+		{
+			description: "nil state",
+			state:       nil,
+			expected:    NewAllocationState(),
+		},
+		{
+			description: "state with multiple owners",
+			state: func() *AllocationState {
+				state := NewAllocationState()
+				state.Assign("pod-a/container-0", devices[0], devices[1])
+				state.Assign("pod-b/container-0", devices[5])
+
+				return state
+			}(),
+			expected: func() *AllocationState {
+				state := NewAllocationState()
+				state.Assign("pod-a/container-0", devices[0], devices[1])
+				state.Assign("pod-b/container-0", devices[5])
+
+				return state
+			}(),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), DefaultCheckpointFileName)
+
+			assert.NoError(t, WriteCheckpoint(path, tc.state))
+
+			actual, err := ReadCheckpoint(path)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+
+			// temporary files must be cleaned up after rename.
+			entries, err := os.ReadDir(filepath.Dir(path))
+			assert.NoError(t, err)
+			assert.Len(t, entries, 1)
+		})
+	}
+}
+
+func TestReadCheckpointErrors(t *testing.T) {
+	t.Run("checkpoint does not exist", func(t *testing.T) {
+		_, err := ReadCheckpoint(filepath.Join(t.TempDir(), DefaultCheckpointFileName))
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("checkpoint is modified", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), DefaultCheckpointFileName)
+
+		state := NewAllocationState()
+		state.Allocations["pod-a"] = []AllocatedDevice{{DeviceID: "A76AAD68-6855-40B1-9E86-D080852D1C80", PCIBusID: "27"}}
+		assert.NoError(t, WriteCheckpoint(path, state))
+
+		raw, err := os.ReadFile(path)
+		assert.NoError(t, err)
+
+		tampered := bytes.Replace(raw, []byte(`"pci_bus_id": "27"`), []byte(`"pci_bus_id": "2a"`), 1)
+		assert.NotEqual(t, raw, tampered)
+		assert.NoError(t, os.WriteFile(path, tampered, DefaultPermissions))
+
+		_, err = ReadCheckpoint(path)
+		assert.True(t, errors.Is(err, ErrChecksumMismatch))
+	})
+
+	t.Run("checkpoint is not a json", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), DefaultCheckpointFileName)
+		assert.NoError(t, os.WriteFile(path, []byte("not a json"), DefaultPermissions))
+
+		_, err := ReadCheckpoint(path)
+		assert.Error(t, err)
+	})
+}
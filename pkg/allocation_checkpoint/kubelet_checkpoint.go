@@ -0,0 +1,185 @@
+package allocation_checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/npu_allocator"
+)
+
+const (
+	DefaultKubeletCheckpointPath = "/var/lib/kubelet/device-plugins/kubelet_internal_checkpoint"
+	DefaultResourceNamePrefix    = "furiosa.ai/"
+)
+
+// KubeletAssignment is a device assignment of a container recorded by kubelet device manager.
+type KubeletAssignment struct {
+	PodUID        string
+	ContainerName string
+	ResourceName  string
+	DeviceIDs     []string
+}
+
+// kubeletPodDevicesEntry mirrors `PodDevicesEntry` of kubelet checkpoint.
+// DeviceIDs is a map of NUMA node to device ids since Kubernetes 1.20, and a plain list of device ids before.
+type kubeletPodDevicesEntry struct {
+	PodUID        string
+	ContainerName string
+	ResourceName  string
+	DeviceIDs     json.RawMessage
+}
+
+type kubeletCheckpoint struct {
+	Data struct {
+		PodDeviceEntries  []kubeletPodDevicesEntry
+		RegisteredDevices map[string][]string
+	}
+}
+
+// parseKubeletDeviceIDs parses DeviceIDs of kubelet checkpoint in both of per NUMA node and legacy formats.
+func parseKubeletDeviceIDs(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var deviceIDs []string
+	if err := json.Unmarshal(raw, &deviceIDs); err == nil {
+		return deviceIDs, nil
+	}
+
+	var devicesPerNUMA map[string][]string
+	if err := json.Unmarshal(raw, &devicesPerNUMA); err != nil {
+		return nil, fmt.Errorf("couldn't parse device ids %s: %w", string(raw), err)
+	}
+
+	numaNodes := make([]string, 0, len(devicesPerNUMA))
+	for numaNode := range devicesPerNUMA {
+		numaNodes = append(numaNodes, numaNode)
+	}
+
+	sort.Strings(numaNodes)
+
+	for _, numaNode := range numaNodes {
+		deviceIDs = append(deviceIDs, devicesPerNUMA[numaNode]...)
+	}
+
+	return deviceIDs, nil
+}
+
+// ReadKubeletCheckpoint reads kubelet device manager checkpoint from the path and returns assignments of the given resources.
+// If resourceNames is empty, assignments of resources prefixed with DefaultResourceNamePrefix are returned.
+// Note that the checksum of the checkpoint is not verified because it depends on the internal struct layout of kubelet.
+func ReadKubeletCheckpoint(path string, resourceNames ...string) ([]KubeletAssignment, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoint kubeletCheckpoint
+	if err = json.Unmarshal(raw, &checkpoint); err != nil {
+		return nil, fmt.Errorf("couldn't parse kubelet checkpoint %s: %w", path, err)
+	}
+
+	isTargetResource := func(resourceName string) bool {
+		if len(resourceNames) == 0 {
+			return strings.HasPrefix(resourceName, DefaultResourceNamePrefix)
+		}
+
+		for _, target := range resourceNames {
+			if resourceName == target {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	var assignments []KubeletAssignment
+	for _, entry := range checkpoint.Data.PodDeviceEntries {
+		if !isTargetResource(entry.ResourceName) {
+			continue
+		}
+
+		deviceIDs, err := parseKubeletDeviceIDs(entry.DeviceIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		assignments = append(assignments, KubeletAssignment{
+			PodUID:        entry.PodUID,
+			ContainerName: entry.ContainerName,
+			ResourceName:  entry.ResourceName,
+			DeviceIDs:     deviceIDs,
+		})
+	}
+
+	return assignments, nil
+}
+
+// overlaps checks whether two partitions of the same card share any core.
+// nil Partition represents the whole card.
+func overlaps(p1, p2 *furiosa_device.Partition) bool {
+	if p1 == nil || p2 == nil {
+		return true
+	}
+
+	return p1.Start <= p2.End && p2.Start <= p1.End
+}
+
+// ResolveKubeletAssignments maps device ids of the assignments back to FuriosaDevices of the inventory and returns in-use DeviceSet.
+// A device id is matched exactly first. If the inventory is built with another PartitioningPolicy,
+// every device of the same card sharing cores with the recorded id is considered in use.
+// Device ids that cannot be mapped to any device are returned as unresolved.
+func ResolveKubeletAssignments(assignments []KubeletAssignment, inventory []furiosa_device.FuriosaDevice) (inUse npu_allocator.DeviceSet, unresolved []string) {
+	type inventoryDevice struct {
+		partition *furiosa_device.Partition
+		device    furiosa_device.FuriosaDevice
+	}
+
+	devicesByID := make(map[string]furiosa_device.FuriosaDevice, len(inventory))
+	devicesByUUID := make(map[string][]inventoryDevice)
+	for _, device := range inventory {
+		devicesByID[device.DeviceID()] = device
+
+		uuid, partition, err := furiosa_device.ParseDeviceID(device.DeviceID())
+		if err != nil {
+			continue
+		}
+
+		devicesByUUID[uuid] = append(devicesByUUID[uuid], inventoryDevice{partition: partition, device: device})
+	}
+
+	inUse = npu_allocator.NewDeviceSet()
+	for _, assignment := range assignments {
+		for _, deviceID := range assignment.DeviceIDs {
+			if device, ok := devicesByID[deviceID]; ok {
+				inUse.Insert(npu_allocator.NewDevice(device))
+				continue
+			}
+
+			uuid, partition, err := furiosa_device.ParseDeviceID(deviceID)
+			if err != nil {
+				unresolved = append(unresolved, deviceID)
+				continue
+			}
+
+			resolved := false
+			for _, candidate := range devicesByUUID[uuid] {
+				if overlaps(partition, candidate.partition) {
+					inUse.Insert(npu_allocator.NewDevice(candidate.device))
+					resolved = true
+				}
+			}
+
+			if !resolved {
+				unresolved = append(unresolved, deviceID)
+			}
+		}
+	}
+
+	return inUse, unresolved
+}
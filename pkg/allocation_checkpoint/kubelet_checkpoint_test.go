@@ -0,0 +1,161 @@
+package allocation_checkpoint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadKubeletCheckpoint(t *testing.T) {
+	tests := []struct {
+		description   string
+		path          string
+		resourceNames []string
+		expected      []KubeletAssignment
+	}{
+		{
+			description: "per NUMA node format, default resource prefix",
+			path:        filepath.Join("testdata", "kubelet_internal_checkpoint"),
+			expected: []KubeletAssignment{
+				{
+					PodUID:        "6f2b1c9e-0f43-4d8a-9d4e-1b7f3e2a5c01",
+					ContainerName: "inference",
+					ResourceName:  "furiosa.ai/rngd-2core",
+					DeviceIDs: []string{
+						"A76AAD68-6855-40B1-9E86-D080852D1C80_cores_0-1",
+						"A76AAD68-6855-40B1-9E86-D080852D1C80_cores_2-3",
+					},
+				},
+				{
+					PodUID:        "9a0c7d51-2f6e-4b3b-8c1d-7e5a4f3b2d10",
+					ContainerName: "worker",
+					ResourceName:  "furiosa.ai/rngd-2core",
+					DeviceIDs:     []string{"A76AAD68-6855-40B1-9E86-D080852D1C87_cores_6-7"},
+				},
+				{
+					PodUID:        "c4e8a2f0-5b19-4e7d-a3c6-0d9f8b7e6a21",
+					ContainerName: "stale",
+					ResourceName:  "furiosa.ai/rngd-2core",
+					DeviceIDs:     []string{"00000000-0000-0000-0000-000000000000_cores_0-1"},
+				},
+			},
+		},
+		{
+			description:   "per NUMA node format, explicit resource name",
+			path:          filepath.Join("testdata", "kubelet_internal_checkpoint"),
+			resourceNames: []string{"nvidia.com/gpu"},
+			expected: []KubeletAssignment{
+				{
+					PodUID:        "9a0c7d51-2f6e-4b3b-8c1d-7e5a4f3b2d10",
+					ContainerName: "worker",
+					ResourceName:  "nvidia.com/gpu",
+					DeviceIDs:     []string{"GPU-1b0d5e2a-4f7c-11ee-be56-0242ac120002"},
+				},
+			},
+		},
+		{
+			description: "legacy format",
+			path:        filepath.Join("testdata", "kubelet_internal_checkpoint_legacy"),
+			expected: []KubeletAssignment{
+				{
+					PodUID:        "6f2b1c9e-0f43-4d8a-9d4e-1b7f3e2a5c01",
+					ContainerName: "inference",
+					ResourceName:  "furiosa.ai/rngd",
+					DeviceIDs: []string{
+						"A76AAD68-6855-40B1-9E86-D080852D1C81",
+						"A76AAD68-6855-40B1-9E86-D080852D1C82",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			actual, err := ReadKubeletCheckpoint(tc.path, tc.resourceNames...)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestResolveKubeletAssignments(t *testing.T) {
+	smiDevices := smi.GetStaticMockDevices(smi.ArchRngd)
+
+	assignments, err := ReadKubeletCheckpoint(filepath.Join("testdata", "kubelet_internal_checkpoint"))
+	assert.NoError(t, err)
+
+	legacyAssignments, err := ReadKubeletCheckpoint(filepath.Join("testdata", "kubelet_internal_checkpoint_legacy"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		description        string
+		assignments        []KubeletAssignment
+		policy             furiosa_device.PartitioningPolicy
+		expectedDeviceIDs  []string
+		expectedUnresolved []string
+	}{
+		{
+			description: "same policy",
+			assignments: assignments,
+			policy:      furiosa_device.DualCorePolicy,
+			expectedDeviceIDs: []string{
+				"A76AAD68-6855-40B1-9E86-D080852D1C80_cores_0-1",
+				"A76AAD68-6855-40B1-9E86-D080852D1C80_cores_2-3",
+				"A76AAD68-6855-40B1-9E86-D080852D1C87_cores_6-7",
+			},
+			expectedUnresolved: []string{"00000000-0000-0000-0000-000000000000_cores_0-1"},
+		},
+		{
+			description: "policy changed to quad core",
+			assignments: assignments,
+			policy:      furiosa_device.QuadCorePolicy,
+			expectedDeviceIDs: []string{
+				"A76AAD68-6855-40B1-9E86-D080852D1C80_cores_0-3",
+				"A76AAD68-6855-40B1-9E86-D080852D1C87_cores_4-7",
+			},
+			expectedUnresolved: []string{"00000000-0000-0000-0000-000000000000_cores_0-1"},
+		},
+		{
+			description: "policy changed to none",
+			assignments: assignments,
+			policy:      furiosa_device.NonePolicy,
+			expectedDeviceIDs: []string{
+				"A76AAD68-6855-40B1-9E86-D080852D1C80",
+				"A76AAD68-6855-40B1-9E86-D080852D1C87",
+			},
+			expectedUnresolved: []string{"00000000-0000-0000-0000-000000000000_cores_0-1"},
+		},
+		{
+			description: "exclusive devices resolved to partitions",
+			assignments: legacyAssignments,
+			policy:      furiosa_device.QuadCorePolicy,
+			expectedDeviceIDs: []string{
+				"A76AAD68-6855-40B1-9E86-D080852D1C81_cores_0-3",
+				"A76AAD68-6855-40B1-9E86-D080852D1C81_cores_4-7",
+				"A76AAD68-6855-40B1-9E86-D080852D1C82_cores_0-3",
+				"A76AAD68-6855-40B1-9E86-D080852D1C82_cores_4-7",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			inventory, err := furiosa_device.NewFuriosaDevices(smiDevices, nil, tc.policy)
+			assert.NoError(t, err)
+
+			inUse, unresolved := ResolveKubeletAssignments(tc.assignments, inventory)
+
+			actualDeviceIDs := make([]string, 0, inUse.Len())
+			for _, device := range inUse.Devices() {
+				actualDeviceIDs = append(actualDeviceIDs, device.ID())
+			}
+
+			assert.Equal(t, tc.expectedDeviceIDs, actualDeviceIDs)
+			assert.Equal(t, tc.expectedUnresolved, unresolved)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package allocation_checkpoint
+
+import (
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+)
+
+// StaleDevice describes a recorded device that no longer matches the current inventory.
+// Current is nil if the device disappeared, otherwise it is the device now placed in the same slot.
+type StaleDevice struct {
+	Owner    string
+	Recorded AllocatedDevice
+	Current  furiosa_device.FuriosaDevice
+}
+
+// ReconcileResult is the outcome of reconciling AllocationState against the current inventory.
+type ReconcileResult struct {
+	// State contains allocations whose devices are still present in the inventory.
+	State *AllocationState
+	// Devices maps owner to FuriosaDevices of the current inventory restored from the checkpoint.
+	Devices map[string][]furiosa_device.FuriosaDevice
+	// Disappeared lists recorded devices that are not found in the inventory.
+	Disappeared []StaleDevice
+	// UUIDChanged lists recorded devices whose slot is now occupied by a device with different UUID.
+	UUIDChanged []StaleDevice
+}
+
+// slotKey identifies a device by its physical location, PCI bus id with partition range if exists.
+func slotKey(pciBusID string, deviceID string) string {
+	_, partition, err := furiosa_device.ParseDeviceID(deviceID)
+	if err != nil || partition == nil {
+		return pciBusID
+	}
+
+	return pciBusID + "/" + partition.String()
+}
+
+// Reconcile restores the given state against the inventory of FuriosaDevice.
+// Devices found with the same DeviceID are restored, others are reported as disappeared or UUID changed.
+func Reconcile(state *AllocationState, inventory []furiosa_device.FuriosaDevice) *ReconcileResult {
+	result := &ReconcileResult{
+		State:   NewAllocationState(),
+		Devices: make(map[string][]furiosa_device.FuriosaDevice),
+	}
+
+	if state == nil {
+		return result
+	}
+
+	devicesByID := make(map[string]furiosa_device.FuriosaDevice, len(inventory))
+	devicesBySlot := make(map[string]furiosa_device.FuriosaDevice, len(inventory))
+	for _, device := range inventory {
+		devicesByID[device.DeviceID()] = device
+		devicesBySlot[slotKey(device.PCIBusID(), device.DeviceID())] = device
+	}
+
+	for _, owner := range state.Owners() {
+		var restored []furiosa_device.FuriosaDevice
+		for _, recorded := range state.Allocations[owner] {
+			if device, ok := devicesByID[recorded.DeviceID]; ok {
+				restored = append(restored, device)
+				continue
+			}
+
+			staleDevice := StaleDevice{Owner: owner, Recorded: recorded}
+			if device, ok := devicesBySlot[slotKey(recorded.PCIBusID, recorded.DeviceID)]; ok {
+				staleDevice.Current = device
+				result.UUIDChanged = append(result.UUIDChanged, staleDevice)
+			} else {
+				result.Disappeared = append(result.Disappeared, staleDevice)
+			}
+		}
+
+		if len(restored) > 0 {
+			result.State.Assign(owner, restored...)
+			result.Devices[owner] = restored
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,91 @@
+package allocation_checkpoint
+
+import (
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+	"github.com/stretchr/testify/assert"
+	"tags.cncf.io/container-device-interface/specs-go"
+)
+
+var _ furiosa_device.FuriosaDevice = (*replacedDevice)(nil)
+
+// replacedDevice simulates a card replaced in the same slot with different UUID.
+type replacedDevice struct {
+	furiosa_device.FuriosaDevice
+	deviceID string
+}
+
+func (r *replacedDevice) DeviceID() string {
+	return r.deviceID
+}
+
+func (r *replacedDevice) CDISpec() (*specs.Device, error) {
+	return nil, nil
+}
+
+func TestReconcile(t *testing.T) {
+	devices, err := furiosa_device.NewFuriosaDevices(smi.GetStaticMockDevices(smi.ArchRngd), nil, furiosa_device.DualCorePolicy)
+	assert.NoError(t, err)
+
+	state := NewAllocationState()
+	state.Assign("pod-a", devices[0], devices[1])
+	state.Assign("pod-b", devices[4], devices[30])
+	state.Assign("pod-c", devices[31])
+
+	t.Run("identical inventory", func(t *testing.T) {
+		actual := Reconcile(state, devices)
+
+		assert.Equal(t, state, actual.State)
+		assert.Equal(t, []furiosa_device.FuriosaDevice{devices[0], devices[1]}, actual.Devices["pod-a"])
+		assert.Empty(t, actual.Disappeared)
+		assert.Empty(t, actual.UUIDChanged)
+	})
+
+	t.Run("last card disappeared", func(t *testing.T) {
+		actual := Reconcile(state, devices[:28])
+
+		assert.Equal(t, []string{"pod-a", "pod-b"}, actual.State.Owners())
+		assert.Equal(t, []string{devices[4].DeviceID()}, actual.State.DeviceIDs("pod-b"))
+		assert.Len(t, actual.Disappeared, 2)
+		assert.Equal(t, "pod-b", actual.Disappeared[0].Owner)
+		assert.Equal(t, devices[30].DeviceID(), actual.Disappeared[0].Recorded.DeviceID)
+		assert.Nil(t, actual.Disappeared[0].Current)
+		assert.Equal(t, "pod-c", actual.Disappeared[1].Owner)
+		assert.Empty(t, actual.UUIDChanged)
+	})
+
+	t.Run("first card replaced", func(t *testing.T) {
+		inventory := make([]furiosa_device.FuriosaDevice, len(devices))
+		copy(inventory, devices)
+
+		for i := 0; i < 4; i++ {
+			_, partition, err := furiosa_device.ParseDeviceID(devices[i].DeviceID())
+			assert.NoError(t, err)
+
+			inventory[i] = &replacedDevice{
+				FuriosaDevice: devices[i],
+				deviceID:      "B76AAD68-6855-40B1-9E86-D080852D1C80_cores_" + partition.String(),
+			}
+		}
+
+		actual := Reconcile(state, inventory)
+
+		assert.Equal(t, []string{"pod-b", "pod-c"}, actual.State.Owners())
+		assert.Empty(t, actual.Disappeared)
+		assert.Len(t, actual.UUIDChanged, 2)
+		for idx, staleDevice := range actual.UUIDChanged {
+			assert.Equal(t, "pod-a", staleDevice.Owner)
+			assert.Equal(t, devices[idx].DeviceID(), staleDevice.Recorded.DeviceID)
+			assert.Equal(t, inventory[idx], staleDevice.Current)
+		}
+	})
+
+	t.Run("nil state", func(t *testing.T) {
+		actual := Reconcile(nil, devices)
+
+		assert.Empty(t, actual.State.Owners())
+		assert.Empty(t, actual.Devices)
+	})
+}
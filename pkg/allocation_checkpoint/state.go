@@ -0,0 +1,75 @@
+package allocation_checkpoint
+
+import (
+	"sort"
+
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+)
+
+// AllocatedDevice is a record of FuriosaDevice assigned to an owner.
+// PCIBusID is recorded along with DeviceID to detect a card replaced in the same slot.
+type AllocatedDevice struct {
+	DeviceID string `json:"device_id"`
+	PCIBusID string `json:"pci_bus_id"`
+}
+
+// NewAllocatedDevice builds AllocatedDevice from the given FuriosaDevice.
+func NewAllocatedDevice(device furiosa_device.FuriosaDevice) AllocatedDevice {
+	return AllocatedDevice{
+		DeviceID: device.DeviceID(),
+		PCIBusID: device.PCIBusID(),
+	}
+}
+
+// AllocationState holds owner to devices mapping of the node.
+// An owner is an arbitrary identifier of the workload, such as pod UID and container name.
+type AllocationState struct {
+	Allocations map[string][]AllocatedDevice `json:"allocations"`
+}
+
+func NewAllocationState() *AllocationState {
+	return &AllocationState{
+		Allocations: make(map[string][]AllocatedDevice),
+	}
+}
+
+// Assign records the given devices to the owner, replacing any previous assignment of the owner.
+func (s *AllocationState) Assign(owner string, devices ...furiosa_device.FuriosaDevice) {
+	allocatedDevices := make([]AllocatedDevice, 0, len(devices))
+	for _, device := range devices {
+		allocatedDevices = append(allocatedDevices, NewAllocatedDevice(device))
+	}
+
+	sort.Slice(allocatedDevices, func(i, j int) bool {
+		return allocatedDevices[i].DeviceID < allocatedDevices[j].DeviceID
+	})
+
+	s.Allocations[owner] = allocatedDevices
+}
+
+// Release removes assignments of the owner.
+func (s *AllocationState) Release(owner string) {
+	delete(s.Allocations, owner)
+}
+
+// Owners returns sorted list of owners.
+func (s *AllocationState) Owners() []string {
+	owners := make([]string, 0, len(s.Allocations))
+	for owner := range s.Allocations {
+		owners = append(owners, owner)
+	}
+
+	sort.Strings(owners)
+
+	return owners
+}
+
+// DeviceIDs returns DeviceIDs assigned to the owner.
+func (s *AllocationState) DeviceIDs(owner string) []string {
+	deviceIDs := make([]string, 0, len(s.Allocations[owner]))
+	for _, allocatedDevice := range s.Allocations[owner] {
+		deviceIDs = append(deviceIDs, allocatedDevice.DeviceID)
+	}
+
+	return deviceIDs
+}
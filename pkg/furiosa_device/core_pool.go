@@ -0,0 +1,275 @@
+package furiosa_device
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/cdi_spec"
+)
+
+var _ CorePool = (*corePool)(nil)
+
+// CorePool exposes a whole card as a pool of PE cores, and carves a partition out of the pool on demand.
+// Unlike partitions built by NewFuriosaDevices, the size of each partition is decided when it is allocated.
+type CorePool interface {
+	Index() int
+	UUID() string
+	PCIBusID() string
+	NUMANode() int
+	CoreNum() int
+
+	// FreeCores returns the number of cores which are not allocated yet.
+	FreeCores() int
+
+	// CanAllocate checks whether an aligned range of `cores` cores is free.
+	CanAllocate(cores int) bool
+
+	// Allocate reserves the lowest free aligned range of `cores` cores, and returns the partitioned device of the range.
+	// A range is aligned if it starts at a multiple of its size and matches a device file of the card such as "npu0pe4-7".
+	// The whole card is always an aligned range.
+	Allocate(cores int) (FuriosaDevice, error)
+
+	// Release returns cores of the partitioned device with the given DeviceID to the pool.
+	Release(deviceID string) error
+}
+
+type corePool struct {
+	mu sync.Mutex
+
+	index      int
+	origin     smi.Device
+	uuid       string
+	pciBusID   string
+	numaNode   int
+	coreNum    int
+	isDisabled bool
+
+	// alignedRanges holds aligned ranges of cores by the size of range, in ascending order of the start core.
+	alignedRanges map[int][]Partition
+	used          []bool
+	allocated     map[string]Partition
+}
+
+// NewCorePools builds a CorePool for each device. Devices in blockedList can't allocate cores.
+func NewCorePools(devices []smi.Device, blockedList []string) ([]CorePool, error) {
+	corePools := make([]CorePool, 0, len(devices))
+	for _, origin := range devices {
+		info, err := origin.DeviceInfo()
+		if err != nil {
+			return nil, err
+		}
+
+		pool, err := NewCorePool(origin, contains(blockedList, info.UUID()))
+		if err != nil {
+			return nil, err
+		}
+
+		corePools = append(corePools, pool)
+	}
+
+	return corePools, nil
+}
+
+func NewCorePool(originDevice smi.Device, isDisabled bool) (CorePool, error) {
+	uuid, pciBusID, numaNode, originIndex, err := parseDeviceInfo(originDevice)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceInfo, err := originDevice.DeviceInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	deviceFiles, err := originDevice.DeviceFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	coreNum := int(deviceInfo.CoreNum())
+
+	return &corePool{
+		index:         originIndex,
+		origin:        originDevice,
+		uuid:          uuid,
+		pciBusID:      pciBusID,
+		numaNode:      int(numaNode),
+		coreNum:       coreNum,
+		isDisabled:    isDisabled,
+		alignedRanges: buildAlignedRanges(deviceFiles, coreNum),
+		used:          make([]bool, coreNum),
+		allocated:     make(map[string]Partition),
+	}, nil
+}
+
+// buildAlignedRanges collects contiguous ranges of device files which start at a multiple of their size.
+func buildAlignedRanges(deviceFiles []smi.DeviceFile, coreNum int) map[int][]Partition {
+	alignedRanges := make(map[int][]Partition)
+	if coreNum > 0 {
+		alignedRanges[coreNum] = []Partition{{Start: 0, End: coreNum - 1}}
+	}
+
+	for _, partition := range deviceFilePartitions(deviceFiles) {
+		size := partition.End - partition.Start + 1
+		if size == coreNum || partition.Start%size != 0 || partition.End >= coreNum {
+			continue
+		}
+
+		alignedRanges[size] = append(alignedRanges[size], partition)
+	}
+
+	for size := range alignedRanges {
+		sort.Slice(alignedRanges[size], func(i, j int) bool {
+			return alignedRanges[size][i].Start < alignedRanges[size][j].Start
+		})
+	}
+
+	return alignedRanges
+}
+
+func (c *corePool) Index() int {
+	return c.index
+}
+
+func (c *corePool) UUID() string {
+	return c.uuid
+}
+
+func (c *corePool) PCIBusID() string {
+	return c.pciBusID
+}
+
+func (c *corePool) NUMANode() int {
+	return c.numaNode
+}
+
+func (c *corePool) CoreNum() int {
+	return c.coreNum
+}
+
+func (c *corePool) FreeCores() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	free := 0
+	for _, used := range c.used {
+		if !used {
+			free++
+		}
+	}
+
+	return free
+}
+
+func (c *corePool) CanAllocate(cores int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.findFreeRange(cores)
+
+	return ok && !c.isDisabled
+}
+
+// findFreeRange must be called with the lock held.
+func (c *corePool) findFreeRange(cores int) (Partition, bool) {
+	for _, partition := range c.alignedRanges[cores] {
+		free := true
+		for core := partition.Start; core <= partition.End; core++ {
+			if c.used[core] {
+				free = false
+				break
+			}
+		}
+
+		if free {
+			return partition, true
+		}
+	}
+
+	return Partition{}, false
+}
+
+func (c *corePool) Allocate(cores int) (FuriosaDevice, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.isDisabled {
+		return nil, fmt.Errorf("device %s is disabled", c.uuid)
+	}
+
+	if _, ok := c.alignedRanges[cores]; !ok {
+		return nil, fmt.Errorf("device %s has no aligned range of %d cores", c.uuid, cores)
+	}
+
+	partition, ok := c.findFreeRange(cores)
+	if !ok {
+		return nil, fmt.Errorf("device %s has no free aligned range of %d cores", c.uuid, cores)
+	}
+
+	renderer, err := cdi_spec.NewPartitionedDeviceSpecRenderer(c.origin, partition.Start, partition.End)
+	if err != nil {
+		return nil, err
+	}
+
+	device := &partitionedDevice{
+		// every partition of the card has a unique start core, so indices never collide.
+		index:      c.index*c.coreNum + partition.Start,
+		origin:     c.origin,
+		renderer:   renderer,
+		uuid:       c.uuid,
+		partition:  partition,
+		pciBusID:   c.pciBusID,
+		numaNode:   c.numaNode,
+		isDisabled: c.isDisabled,
+	}
+
+	for core := partition.Start; core <= partition.End; core++ {
+		c.used[core] = true
+	}
+
+	c.allocated[device.DeviceID()] = partition
+
+	return device, nil
+}
+
+func (c *corePool) Release(deviceID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	partition, ok := c.allocated[deviceID]
+	if !ok {
+		return fmt.Errorf("device %s is not allocated from the core pool of device %s", deviceID, c.uuid)
+	}
+
+	for core := partition.Start; core <= partition.End; core++ {
+		c.used[core] = false
+	}
+
+	delete(c.allocated, deviceID)
+
+	return nil
+}
+
+// AllocateCores allocates `cores` cores from the most occupied card that still has a free aligned range, to keep
+// larger ranges of other cards available. Ties are broken by the lower index of the card.
+func AllocateCores(corePools []CorePool, cores int) (FuriosaDevice, error) {
+	var selected CorePool
+	for _, pool := range corePools {
+		if !pool.CanAllocate(cores) {
+			continue
+		}
+
+		if selected == nil || pool.FreeCores() < selected.FreeCores() ||
+			(pool.FreeCores() == selected.FreeCores() && pool.Index() < selected.Index()) {
+			selected = pool
+		}
+	}
+
+	if selected == nil {
+		return nil, fmt.Errorf("no device has a free aligned range of %d cores", cores)
+	}
+
+	return selected.Allocate(cores)
+}
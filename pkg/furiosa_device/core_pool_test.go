@@ -0,0 +1,102 @@
+package furiosa_device
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorePool(t *testing.T) {
+	pool, err := NewCorePool(smi.GetStaticMockDevices(smi.ArchRngd)[0], false)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, pool.CoreNum())
+
+	allocate := func(cores int) FuriosaDevice {
+		device, err := pool.Allocate(cores)
+		assert.NoError(t, err)
+
+		return device
+	}
+
+	quad := allocate(4)
+	assert.Equal(t, "A76AAD68-6855-40B1-9E86-D080852D1C80_cores_0-3", quad.DeviceID())
+
+	dual := allocate(2)
+	assert.Equal(t, "A76AAD68-6855-40B1-9E86-D080852D1C80_cores_4-5", dual.DeviceID())
+	assert.NotEqual(t, quad.Index(), dual.Index())
+
+	single := allocate(1)
+	assert.Equal(t, "A76AAD68-6855-40B1-9E86-D080852D1C80_cores_6", single.DeviceID())
+	assert.Equal(t, 1, pool.FreeCores())
+
+	t.Run("no free aligned range", func(t *testing.T) {
+		assert.False(t, pool.CanAllocate(2))
+		_, err := pool.Allocate(2)
+		assert.Error(t, err)
+	})
+
+	t.Run("range not matching any device file", func(t *testing.T) {
+		_, err := pool.Allocate(3)
+		assert.Error(t, err)
+	})
+
+	t.Run("render CDI spec of the range", func(t *testing.T) {
+		spec, err := dual.CDISpec()
+		assert.NoError(t, err)
+
+		var partitionNodes []string
+		for _, deviceNode := range spec.ContainerEdits.DeviceNodes {
+			if strings.Contains(deviceNode.Path, "pe") {
+				partitionNodes = append(partitionNodes, deviceNode.Path)
+			}
+		}
+
+		assert.ElementsMatch(t, []string{"/dev/rngd/npu0pe4", "/dev/rngd/npu0pe5", "/dev/rngd/npu0pe4-5"}, partitionNodes)
+	})
+
+	t.Run("release returns cores to the pool", func(t *testing.T) {
+		assert.NoError(t, pool.Release(quad.DeviceID()))
+		assert.Error(t, pool.Release(quad.DeviceID()))
+		assert.Equal(t, 5, pool.FreeCores())
+
+		reallocated := allocate(4)
+		assert.Equal(t, quad.DeviceID(), reallocated.DeviceID())
+	})
+
+	t.Run("whole card", func(t *testing.T) {
+		assert.NoError(t, pool.Release(dual.DeviceID()))
+		assert.NoError(t, pool.Release(single.DeviceID()))
+		assert.NoError(t, pool.Release(quad.DeviceID()))
+
+		whole := allocate(8)
+		assert.Equal(t, "A76AAD68-6855-40B1-9E86-D080852D1C80_cores_0-7", whole.DeviceID())
+	})
+}
+
+func TestAllocateCores(t *testing.T) {
+	devices := smi.GetStaticMockDevices(smi.ArchRngd)[:3]
+
+	pools, err := NewCorePools(devices, []string{"A76AAD68-6855-40B1-9E86-D080852D1C80"})
+	assert.NoError(t, err)
+
+	_, err = pools[0].Allocate(1)
+	assert.Error(t, err)
+
+	first, err := AllocateCores(pools, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "A76AAD68-6855-40B1-9E86-D080852D1C81_cores_0-3", first.DeviceID())
+
+	// the most occupied card is chosen to keep the other card whole.
+	second, err := AllocateCores(pools, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "A76AAD68-6855-40B1-9E86-D080852D1C81_cores_4-5", second.DeviceID())
+
+	third, err := AllocateCores(pools, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "A76AAD68-6855-40B1-9E86-D080852D1C82_cores_0-3", third.DeviceID())
+
+	_, err = AllocateCores(pools, 8)
+	assert.Error(t, err)
+}
@@ -1,6 +1,8 @@
 package furiosa_device
 
 import (
+	"fmt"
+
 	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
 	"tags.cncf.io/container-device-interface/specs-go"
 )
@@ -15,21 +17,74 @@ type FuriosaDevice interface {
 }
 
 func NewFuriosaDevices(devices []smi.Device, blockedList []string, policy PartitioningPolicy) ([]FuriosaDevice, error) {
-	var furiosaDevices []FuriosaDevice
-	var newDevFunc = newDeviceFuncResolver(policy)
+	return NewFuriosaDevicesWithLayouts(devices, blockedList, policy, nil)
+}
+
+// NewFuriosaDevicesWithLayouts is same as NewFuriosaDevices, except that cards in layouts are partitioned with
+// the PartitionLayout of their UUID instead of the policy. Every layout is validated against the card.
+func NewFuriosaDevicesWithLayouts(devices []smi.Device, blockedList []string, policy PartitioningPolicy, layouts map[string]PartitionLayout) ([]FuriosaDevice, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid partitioning policy %q: %w", policy, err)
+	}
+
+	layoutsOfDevices := make([]PartitionLayout, 0, len(devices))
+	partitionsLength := 1
 	for _, origin := range devices {
 		info, err := origin.DeviceInfo()
 		if err != nil {
 			return nil, err
 		}
 
+		layout, ok := layouts[info.UUID()]
+		if !ok {
+			if layout, err = policy.Layout(int(info.CoreNum())); err != nil {
+				return nil, err
+			}
+		}
+
+		if layout != nil {
+			deviceFiles, err := origin.DeviceFiles()
+			if err != nil {
+				return nil, err
+			}
+
+			if err = layout.Validate(int(info.CoreNum()), deviceFiles); err != nil {
+				return nil, fmt.Errorf("invalid partition layout for device %s: %w", info.UUID(), err)
+			}
+		}
+
+		layoutsOfDevices = append(layoutsOfDevices, layout)
+		partitionsLength = max(partitionsLength, len(layout))
+	}
+
+	var furiosaDevices []FuriosaDevice
+	for idx, origin := range devices {
+		info, err := origin.DeviceInfo()
+		if err != nil {
+			return nil, err
+		}
+
 		isDisabled := contains(blockedList, info.UUID())
-		newDevices, err := newDevFunc(origin, isDisabled)
+		if layoutsOfDevices[idx] == nil {
+			newExclusiveDevice, err := newExclusiveDevice(origin, isDisabled)
+			if err != nil {
+				return nil, err
+			}
+
+			// regard the whole card as the first partition, to keep its index apart from partitions of other cards.
+			newExclusiveDevice.index = generateIndexForPartitionedDevice(newExclusiveDevice.index, 0, partitionsLength)
+			furiosaDevices = append(furiosaDevices, newExclusiveDevice)
+
+			continue
+		}
+
+		newPartitionedDevices, err := newPartitionedDevicesWithLayout(origin, layoutsOfDevices[idx], partitionsLength, isDisabled)
 		if err != nil {
 			return nil, err
 		}
 
-		furiosaDevices = append(furiosaDevices, newDevices...)
+		furiosaDevices = append(furiosaDevices, newPartitionedDevices...)
 	}
+
 	return furiosaDevices, nil
 }
@@ -18,7 +18,7 @@ type exclusiveDevice struct {
 	isDisabled bool
 }
 
-func newExclusiveDevice(originDevice smi.Device, isDisabled bool) (FuriosaDevice, error) {
+func newExclusiveDevice(originDevice smi.Device, isDisabled bool) (*exclusiveDevice, error) {
 	deviceID, pciBusID, numaNode, originIndex, err := parseDeviceInfo(originDevice)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,218 @@
+package furiosa_device
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+)
+
+var _ Inventory = (*inventory)(nil)
+
+// AllocationConflictError is returned when a device overlaps cores of a device which is already allocated,
+// or of another device in the same request.
+type AllocationConflictError struct {
+	DeviceID            string
+	ConflictingDeviceID string
+
+	// Owner is the owner of ConflictingDeviceID, or empty if the conflict is within the request.
+	Owner string
+}
+
+func (e *AllocationConflictError) Error() string {
+	if e.Owner == "" {
+		return fmt.Sprintf("device %s conflicts with device %s in the same request", e.DeviceID, e.ConflictingDeviceID)
+	}
+
+	return fmt.Sprintf("device %s conflicts with device %s allocated to %s", e.DeviceID, e.ConflictingDeviceID, e.Owner)
+}
+
+// Inventory advertises each card through several views at once, such as a whole card and its partitions.
+// Devices of different views share cores of the same card, so allocating one makes every overlapping device unavailable.
+type Inventory interface {
+	// Devices returns every device of the view.
+	Devices(view PartitioningPolicy) []FuriosaDevice
+
+	// Available returns devices of the view which don't overlap any allocated device.
+	Available(view PartitioningPolicy) []FuriosaDevice
+
+	// Allocate records the devices to the owner. It allocates nothing and returns AllocationConflictError
+	// if any of the devices overlaps an allocated device or another device of the request.
+	Allocate(owner string, deviceIDs ...string) error
+
+	// Release removes every device of the owner from the ledger.
+	Release(owner string) error
+
+	// Allocations returns the ledger as device IDs by owner.
+	Allocations() map[string][]string
+}
+
+type inventoryEntry struct {
+	device    FuriosaDevice
+	uuid      string
+	partition Partition
+}
+
+func (e inventoryEntry) overlaps(other inventoryEntry) bool {
+	return e.uuid == other.uuid && e.partition.Start <= other.partition.End && other.partition.Start <= e.partition.End
+}
+
+type inventory struct {
+	mu sync.Mutex
+
+	views   map[PartitioningPolicy][]FuriosaDevice
+	entries map[string]inventoryEntry
+
+	ledger  map[string][]string
+	ownerOf map[string]string
+}
+
+// NewInventory builds devices of each view, where NonePolicy is the view of whole cards.
+func NewInventory(devices []smi.Device, blockedList []string, views ...PartitioningPolicy) (Inventory, error) {
+	if len(views) == 0 {
+		return nil, fmt.Errorf("at least one view is required")
+	}
+
+	coreNums := make(map[string]int, len(devices))
+	for _, origin := range devices {
+		info, err := origin.DeviceInfo()
+		if err != nil {
+			return nil, err
+		}
+
+		coreNums[info.UUID()] = int(info.CoreNum())
+	}
+
+	inv := &inventory{
+		views:   make(map[PartitioningPolicy][]FuriosaDevice, len(views)),
+		entries: make(map[string]inventoryEntry),
+		ledger:  make(map[string][]string),
+		ownerOf: make(map[string]string),
+	}
+
+	for _, view := range views {
+		if _, ok := inv.views[view]; ok {
+			return nil, fmt.Errorf("view %q is duplicated", view)
+		}
+
+		furiosaDevices, err := NewFuriosaDevices(devices, blockedList, view)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, furiosaDevice := range furiosaDevices {
+			uuid, partition, err := ParseDeviceID(furiosaDevice.DeviceID())
+			if err != nil {
+				return nil, err
+			}
+
+			entry := inventoryEntry{device: furiosaDevice, uuid: uuid}
+			if partition != nil {
+				entry.partition = *partition
+			} else {
+				entry.partition = Partition{Start: 0, End: coreNums[uuid] - 1}
+			}
+
+			inv.entries[furiosaDevice.DeviceID()] = entry
+		}
+
+		inv.views[view] = furiosaDevices
+	}
+
+	return inv, nil
+}
+
+func (i *inventory) Devices(view PartitioningPolicy) []FuriosaDevice {
+	return append([]FuriosaDevice(nil), i.views[view]...)
+}
+
+func (i *inventory) Available(view PartitioningPolicy) []FuriosaDevice {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	available := make([]FuriosaDevice, 0)
+	for _, furiosaDevice := range i.views[view] {
+		if _, ok := i.findConflict(i.entries[furiosaDevice.DeviceID()]); !ok {
+			available = append(available, furiosaDevice)
+		}
+	}
+
+	return available
+}
+
+// findConflict returns an allocated device overlapping the entry. It must be called with the lock held.
+func (i *inventory) findConflict(entry inventoryEntry) (string, bool) {
+	for allocatedID := range i.ownerOf {
+		if entry.overlaps(i.entries[allocatedID]) {
+			return allocatedID, true
+		}
+	}
+
+	return "", false
+}
+
+func (i *inventory) Allocate(owner string, deviceIDs ...string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	requested := make([]inventoryEntry, 0, len(deviceIDs))
+	for _, deviceID := range deviceIDs {
+		entry, ok := i.entries[deviceID]
+		if !ok {
+			return fmt.Errorf("unknown device %s", deviceID)
+		}
+
+		if conflictingID, ok := i.findConflict(entry); ok {
+			return &AllocationConflictError{DeviceID: deviceID, ConflictingDeviceID: conflictingID, Owner: i.ownerOf[conflictingID]}
+		}
+
+		for _, other := range requested {
+			if entry.overlaps(other) {
+				return &AllocationConflictError{DeviceID: deviceID, ConflictingDeviceID: other.device.DeviceID()}
+			}
+		}
+
+		requested = append(requested, entry)
+	}
+
+	for _, deviceID := range deviceIDs {
+		i.ownerOf[deviceID] = owner
+	}
+
+	i.ledger[owner] = append(i.ledger[owner], deviceIDs...)
+
+	return nil
+}
+
+func (i *inventory) Release(owner string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	deviceIDs, ok := i.ledger[owner]
+	if !ok {
+		return fmt.Errorf("owner %s has no allocation", owner)
+	}
+
+	for _, deviceID := range deviceIDs {
+		delete(i.ownerOf, deviceID)
+	}
+
+	delete(i.ledger, owner)
+
+	return nil
+}
+
+func (i *inventory) Allocations() map[string][]string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	allocations := make(map[string][]string, len(i.ledger))
+	for owner, deviceIDs := range i.ledger {
+		sorted := append([]string(nil), deviceIDs...)
+		sort.Strings(sorted)
+		allocations[owner] = sorted
+	}
+
+	return allocations
+}
@@ -0,0 +1,94 @@
+package furiosa_device
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	card0 = "A76AAD68-6855-40B1-9E86-D080852D1C80"
+	card1 = "A76AAD68-6855-40B1-9E86-D080852D1C81"
+)
+
+func deviceIDsOf(devices []FuriosaDevice) []string {
+	ids := make([]string, 0, len(devices))
+	for _, device := range devices {
+		ids = append(ids, device.DeviceID())
+	}
+
+	return ids
+}
+
+func TestInventory(t *testing.T) {
+	sut, err := NewInventory(smi.GetStaticMockDevices(smi.ArchRngd)[:2], nil, NonePolicy, DualCorePolicy, QuadCorePolicy)
+	assert.NoError(t, err)
+
+	assert.Len(t, sut.Devices(NonePolicy), 2)
+	assert.Len(t, sut.Devices(DualCorePolicy), 8)
+	assert.Len(t, sut.Available(QuadCorePolicy), 4)
+
+	t.Run("allocated partition makes the whole card unavailable", func(t *testing.T) {
+		assert.NoError(t, sut.Allocate("pod-a", card0+"_cores_2-3"))
+
+		assert.Equal(t, []string{card1}, deviceIDsOf(sut.Available(NonePolicy)))
+		assert.Equal(t, []string{card0 + "_cores_4-7", card1 + "_cores_0-3", card1 + "_cores_4-7"}, deviceIDsOf(sut.Available(QuadCorePolicy)))
+		assert.Len(t, sut.Available(DualCorePolicy), 7)
+	})
+
+	t.Run("allocated whole card makes its partitions unavailable", func(t *testing.T) {
+		assert.NoError(t, sut.Allocate("pod-b", card1))
+
+		assert.Empty(t, sut.Available(NonePolicy))
+		assert.Equal(t, []string{card0 + "_cores_0-1", card0 + "_cores_4-5", card0 + "_cores_6-7"}, deviceIDsOf(sut.Available(DualCorePolicy)))
+	})
+
+	t.Run("conflicting allocation is refused", func(t *testing.T) {
+		err := sut.Allocate("pod-c", card0+"_cores_4-5", card1+"_cores_0-3")
+
+		var conflict *AllocationConflictError
+		assert.True(t, errors.As(err, &conflict))
+		assert.Equal(t, card1+"_cores_0-3", conflict.DeviceID)
+		assert.Equal(t, card1, conflict.ConflictingDeviceID)
+		assert.Equal(t, "pod-b", conflict.Owner)
+
+		// nothing is allocated from the refused request.
+		assert.NotContains(t, sut.Allocations(), "pod-c")
+		assert.Len(t, sut.Available(DualCorePolicy), 3)
+	})
+
+	t.Run("conflict within the request is refused", func(t *testing.T) {
+		err := sut.Allocate("pod-d", card0+"_cores_4-7", card0+"_cores_6-7")
+
+		var conflict *AllocationConflictError
+		assert.True(t, errors.As(err, &conflict))
+		assert.Empty(t, conflict.Owner)
+	})
+
+	t.Run("unknown device is refused", func(t *testing.T) {
+		assert.Error(t, sut.Allocate("pod-e", "unknown"))
+	})
+
+	t.Run("release restores availability", func(t *testing.T) {
+		assert.Equal(t, map[string][]string{"pod-a": {card0 + "_cores_2-3"}, "pod-b": {card1}}, sut.Allocations())
+
+		assert.NoError(t, sut.Release("pod-a"))
+		assert.NoError(t, sut.Release("pod-b"))
+		assert.Error(t, sut.Release("pod-b"))
+
+		assert.Len(t, sut.Available(NonePolicy), 2)
+		assert.Len(t, sut.Available(DualCorePolicy), 8)
+	})
+}
+
+func TestNewInventoryWithInvalidViews(t *testing.T) {
+	devices := smi.GetStaticMockDevices(smi.ArchRngd)
+
+	_, err := NewInventory(devices, nil)
+	assert.Error(t, err)
+
+	_, err = NewInventory(devices, nil, NonePolicy, NonePolicy)
+	assert.Error(t, err)
+}
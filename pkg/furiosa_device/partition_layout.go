@@ -0,0 +1,129 @@
+package furiosa_device
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+)
+
+// PartitionLayout is a list of non-overlapping partitions of a card in ascending order of cores.
+// Partitions may have different sizes, and cores which don't belong to any partition are left unused.
+type PartitionLayout []Partition
+
+// ParsePartitionLayout parses either sizes of partitions laid out from core 0 such as "4+2+1+1",
+// or explicit core ranges such as "0-3,4-5,6,7". A single number such as "4" is regarded as a size.
+func ParsePartitionLayout(s string) (PartitionLayout, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty partition layout")
+	}
+
+	var layout PartitionLayout
+	if strings.ContainsAny(s, ",-") {
+		for _, rawPartition := range strings.Split(s, ",") {
+			partition, err := ParsePartition(strings.TrimSpace(rawPartition))
+			if err != nil {
+				return nil, fmt.Errorf("couldn't parse partition layout %q: %w", s, err)
+			}
+
+			layout = append(layout, partition)
+		}
+	} else {
+		start := 0
+		for _, rawSize := range strings.Split(s, "+") {
+			size, err := strconv.Atoi(strings.TrimSpace(rawSize))
+			if err != nil {
+				return nil, fmt.Errorf("couldn't parse partition layout %q: %w", s, err)
+			}
+
+			if size < 1 {
+				return nil, fmt.Errorf("invalid partition layout %q: size of partition must be positive", s)
+			}
+
+			layout = append(layout, Partition{Start: start, End: start + size - 1})
+			start += size
+		}
+	}
+
+	sort.Slice(layout, func(i, j int) bool {
+		return layout[i].Start < layout[j].Start
+	})
+
+	for idx := 1; idx < len(layout); idx++ {
+		if layout[idx].Start <= layout[idx-1].End {
+			return nil, fmt.Errorf("invalid partition layout %q: partitions %s and %s overlap", s, layout[idx-1], layout[idx])
+		}
+	}
+
+	return layout, nil
+}
+
+// newUniformPartitionLayout splits coreNum cores into partitions of coreSize cores.
+func newUniformPartitionLayout(coreSize int, coreNum int) PartitionLayout {
+	layout := make(PartitionLayout, 0, coreNum/coreSize)
+	for start := 0; start+coreSize <= coreNum; start += coreSize {
+		layout = append(layout, Partition{Start: start, End: start + coreSize - 1})
+	}
+
+	return layout
+}
+
+func (l PartitionLayout) String() string {
+	partitions := make([]string, 0, len(l))
+	for _, partition := range l {
+		partitions = append(partitions, partition.String())
+	}
+
+	return strings.Join(partitions, ",")
+}
+
+// Validate checks whether every partition is within coreNum cores and can be rendered,
+// which means the partition is either the whole card or has a device file covering exactly its cores such as "npu0pe4-5".
+func (l PartitionLayout) Validate(coreNum int, deviceFiles []smi.DeviceFile) error {
+	if len(l) == 0 {
+		return fmt.Errorf("partition layout has no partition")
+	}
+
+	renderable := make(map[Partition]bool)
+	for _, partition := range deviceFilePartitions(deviceFiles) {
+		renderable[partition] = true
+	}
+
+	for _, partition := range l {
+		if partition.End >= coreNum {
+			return fmt.Errorf("partition %s of layout %s exceeds %d cores", partition, l, coreNum)
+		}
+
+		if partition.Start == 0 && partition.End == coreNum-1 {
+			continue
+		}
+
+		if !renderable[partition] {
+			return fmt.Errorf("partition %s of layout %s has no matching device file", partition, l)
+		}
+	}
+
+	return nil
+}
+
+// deviceFilePartitions returns core ranges of device files covering contiguous cores.
+func deviceFilePartitions(deviceFiles []smi.DeviceFile) []Partition {
+	partitions := make([]Partition, 0, len(deviceFiles))
+	for _, deviceFile := range deviceFiles {
+		cores := deviceFile.Cores()
+		if len(cores) == 0 {
+			continue
+		}
+
+		start, end := int(cores[0]), int(cores[len(cores)-1])
+		if end-start+1 != len(cores) {
+			continue
+		}
+
+		partitions = append(partitions, Partition{Start: start, End: end})
+	}
+
+	return partitions
+}
@@ -0,0 +1,145 @@
+package furiosa_device
+
+import (
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePartitionLayout(t *testing.T) {
+	tests := []struct {
+		description string
+		layout      string
+		expected    PartitionLayout
+		expectError bool
+	}{
+		{
+			description: "sizes of partitions",
+			layout:      "4+2+1+1",
+			expected:    PartitionLayout{{Start: 0, End: 3}, {Start: 4, End: 5}, {Start: 6, End: 6}, {Start: 7, End: 7}},
+		},
+		{
+			description: "single size",
+			layout:      "4",
+			expected:    PartitionLayout{{Start: 0, End: 3}},
+		},
+		{
+			description: "explicit core ranges in any order",
+			layout:      "6-7, 0-3,5",
+			expected:    PartitionLayout{{Start: 0, End: 3}, {Start: 5, End: 5}, {Start: 6, End: 7}},
+		},
+		{
+			description: "empty layout",
+			layout:      "",
+			expectError: true,
+		},
+		{
+			description: "zero size",
+			layout:      "4+0",
+			expectError: true,
+		},
+		{
+			description: "overlapping ranges",
+			layout:      "0-3,2-3",
+			expectError: true,
+		},
+		{
+			description: "malformed size",
+			layout:      "4+x",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			actual, err := ParsePartitionLayout(tc.layout)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestValidatePartitionLayout(t *testing.T) {
+	deviceFiles, err := smi.GetStaticMockDevices(smi.ArchRngd)[0].DeviceFiles()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		description string
+		layout      string
+		expectError bool
+	}{
+		{description: "non-uniform layout", layout: "4+2+1+1"},
+		{description: "partial layout", layout: "4-7"},
+		{description: "whole card", layout: "8"},
+		{description: "exceeding cores", layout: "4+4+1", expectError: true},
+		{description: "no device file of three cores", layout: "3+1+4", expectError: true},
+		{description: "no device file of unaligned range", layout: "1-2", expectError: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			layout, err := ParsePartitionLayout(tc.layout)
+			assert.NoError(t, err)
+
+			err = layout.Validate(totalCoresOfRNGD, deviceFiles)
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewFuriosaDevicesWithLayouts(t *testing.T) {
+	devices := smi.GetStaticMockDevices(smi.ArchRngd)[:3]
+
+	t.Run("layout policy", func(t *testing.T) {
+		actual, err := NewFuriosaDevices(devices, nil, PartitioningPolicy("4+2+1+1"))
+		assert.NoError(t, err)
+		assert.Len(t, actual, 12)
+		assert.Equal(t, "A76AAD68-6855-40B1-9E86-D080852D1C81_cores_4-5", actual[5].DeviceID())
+		assert.Equal(t, 5, actual[5].Index())
+	})
+
+	t.Run("layouts per card with the largest partition count for indexing", func(t *testing.T) {
+		actual, err := NewFuriosaDevicesWithLayouts(devices, nil, NonePolicy, map[string]PartitionLayout{
+			"A76AAD68-6855-40B1-9E86-D080852D1C81": {{Start: 0, End: 3}, {Start: 4, End: 7}},
+			"A76AAD68-6855-40B1-9E86-D080852D1C82": {{Start: 0, End: 0}, {Start: 1, End: 1}, {Start: 2, End: 3}},
+		})
+		assert.NoError(t, err)
+
+		ids := make([]string, 0, len(actual))
+		indices := make([]int, 0, len(actual))
+		for _, device := range actual {
+			ids = append(ids, device.DeviceID())
+			indices = append(indices, device.Index())
+		}
+
+		assert.Equal(t, []string{
+			"A76AAD68-6855-40B1-9E86-D080852D1C80",
+			"A76AAD68-6855-40B1-9E86-D080852D1C81_cores_0-3",
+			"A76AAD68-6855-40B1-9E86-D080852D1C81_cores_4-7",
+			"A76AAD68-6855-40B1-9E86-D080852D1C82_cores_0",
+			"A76AAD68-6855-40B1-9E86-D080852D1C82_cores_1",
+			"A76AAD68-6855-40B1-9E86-D080852D1C82_cores_2-3",
+		}, ids)
+		assert.Equal(t, []int{0, 3, 4, 6, 7, 8}, indices)
+	})
+
+	t.Run("invalid policy is an error instead of panic", func(t *testing.T) {
+		_, err := NewFuriosaDevices(devices, nil, PartitioningPolicy("triple-core"))
+		assert.Error(t, err)
+	})
+
+	t.Run("layout without device file is an error", func(t *testing.T) {
+		_, err := NewFuriosaDevices(devices, nil, PartitioningPolicy("3+3+2"))
+		assert.Error(t, err)
+	})
+}
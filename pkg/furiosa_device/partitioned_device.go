@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/cdi_spec"
 	"strconv"
+	"strings"
 	"tags.cncf.io/container-device-interface/specs-go"
 
-	"github.com/bradfitz/iter"
 	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
 )
 
@@ -31,6 +31,51 @@ func (p Partition) String() string {
 	return fmt.Sprintf("%d-%d", p.Start, p.End)
 }
 
+// ParsePartition parses the string representation of Partition such as "0" or "0-3".
+func ParsePartition(s string) (Partition, error) {
+	start, end, found := strings.Cut(s, "-")
+	if !found {
+		end = start
+	}
+
+	startCore, err := strconv.Atoi(start)
+	if err != nil {
+		return Partition{}, fmt.Errorf("couldn't parse partition %q: %w", s, err)
+	}
+
+	endCore, err := strconv.Atoi(end)
+	if err != nil {
+		return Partition{}, fmt.Errorf("couldn't parse partition %q: %w", s, err)
+	}
+
+	if startCore < 0 || startCore > endCore {
+		return Partition{}, fmt.Errorf("invalid partition %q: start core must be less than or equal to end core", s)
+	}
+
+	return Partition{Start: startCore, End: endCore}, nil
+}
+
+// ParseDeviceID splits DeviceID of FuriosaDevice into the UUID of the origin device and the Partition.
+// Partition is nil if the given DeviceID represents an exclusive device.
+// e.g. "a3e78042-9cc7-4344-9541-d2d3ffd28106_cores_0-1" returns "a3e78042-9cc7-4344-9541-d2d3ffd28106" and Partition{0, 1}.
+func ParseDeviceID(deviceID string) (uuid string, partition *Partition, err error) {
+	uuid, rawPartition, found := strings.Cut(deviceID, deviceIdDelimiter)
+	if uuid == "" {
+		return "", nil, fmt.Errorf("couldn't parse device id %q: empty uuid", deviceID)
+	}
+
+	if !found {
+		return uuid, nil, nil
+	}
+
+	parsed, err := ParsePartition(rawPartition)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return uuid, &parsed, nil
+}
+
 type partitionedDevice struct {
 	index      int
 	origin     smi.Device
@@ -49,25 +94,28 @@ func generateIndexForPartitionedDevice(originalIndex, partitionIndex, partitions
 
 // newPartitionedDevices returns list of FuriosaDevice based on given config.ResourceUnitStrategy.
 func newPartitionedDevices(originDevice smi.Device, numOfCoresPerPartition int, numOfPartitions int, isDisabled bool) ([]FuriosaDevice, error) {
+	layout := newUniformPartitionLayout(numOfCoresPerPartition, numOfCoresPerPartition*numOfPartitions)
+
+	return newPartitionedDevicesWithLayout(originDevice, layout, numOfPartitions, isDisabled)
+}
+
+// newPartitionedDevicesWithLayout returns FuriosaDevice for each partition of the layout.
+// partitionsLength must be the largest number of partitions among cards, so that indices of different cards never collide.
+func newPartitionedDevicesWithLayout(originDevice smi.Device, layout PartitionLayout, partitionsLength int, isDisabled bool) ([]FuriosaDevice, error) {
 	uuid, pciBusID, numaNode, originIndex, err := parseDeviceInfo(originDevice)
 	if err != nil {
 		return nil, err
 	}
 
-	partitionedDevices := make([]FuriosaDevice, 0)
-	for partitionIndex := range iter.N(numOfPartitions) {
-		partition := Partition{
-			Start: partitionIndex * numOfCoresPerPartition,
-			End:   (partitionIndex+1)*numOfCoresPerPartition - 1,
-		}
-
+	partitionedDevices := make([]FuriosaDevice, 0, len(layout))
+	for partitionIndex, partition := range layout {
 		partitionedManifest, err := cdi_spec.NewPartitionedDeviceSpecRenderer(originDevice, partition.Start, partition.End)
 		if err != nil {
 			return nil, err
 		}
 
 		partitionedDevices = append(partitionedDevices, &partitionedDevice{
-			index:      generateIndexForPartitionedDevice(originIndex, partitionIndex, numOfPartitions),
+			index:      generateIndexForPartitionedDevice(originIndex, partitionIndex, partitionsLength),
 			origin:     originDevice,
 			renderer:   partitionedManifest,
 			uuid:       uuid,
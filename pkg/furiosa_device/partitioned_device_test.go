@@ -852,3 +852,63 @@ func TestID_RNGD_PartitionedDevice(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDeviceID(t *testing.T) {
+	rngdMockDeviceUUID := "A76AAD68-6855-40B1-9E86-D080852D1C80"
+
+	tests := []struct {
+		description       string
+		deviceID          string
+		expectedUUID      string
+		expectedPartition *Partition
+		expectError       bool
+	}{
+		{
+			description:       "exclusive device id",
+			deviceID:          rngdMockDeviceUUID,
+			expectedUUID:      rngdMockDeviceUUID,
+			expectedPartition: nil,
+		},
+		{
+			description:       "single core partition",
+			deviceID:          fmt.Sprintf("%s%s%s", rngdMockDeviceUUID, deviceIdDelimiter, "5"),
+			expectedUUID:      rngdMockDeviceUUID,
+			expectedPartition: &Partition{Start: 5, End: 5},
+		},
+		{
+			description:       "quad core partition",
+			deviceID:          fmt.Sprintf("%s%s%s", rngdMockDeviceUUID, deviceIdDelimiter, "4-7"),
+			expectedUUID:      rngdMockDeviceUUID,
+			expectedPartition: &Partition{Start: 4, End: 7},
+		},
+		{
+			description: "malformed partition",
+			deviceID:    fmt.Sprintf("%s%s%s", rngdMockDeviceUUID, deviceIdDelimiter, "a-b"),
+			expectError: true,
+		},
+		{
+			description: "reversed partition",
+			deviceID:    fmt.Sprintf("%s%s%s", rngdMockDeviceUUID, deviceIdDelimiter, "3-0"),
+			expectError: true,
+		},
+		{
+			description: "empty device id",
+			deviceID:    "",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			uuid, partition, err := ParseDeviceID(tc.deviceID)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedUUID, uuid)
+			assert.Equal(t, tc.expectedPartition, partition)
+		})
+	}
+}
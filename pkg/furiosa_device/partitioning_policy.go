@@ -1,9 +1,5 @@
 package furiosa_device
 
-import (
-	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
-)
-
 type PartitioningPolicy string
 
 const (
@@ -13,7 +9,8 @@ const (
 	QuadCorePolicy   PartitioningPolicy = "quad-core"
 )
 
-// CoreSize returns the number of cores per partition
+// CoreSize returns the number of cores per partition of uniform policies.
+// It returns 0 for NonePolicy and layout policies, whose partitions have no single size.
 func (strategy PartitioningPolicy) CoreSize() int {
 	switch strategy {
 	case SingleCorePolicy:
@@ -25,46 +22,31 @@ func (strategy PartitioningPolicy) CoreSize() int {
 	case QuadCorePolicy:
 		return 4
 
-	default: // CoreSize should not be used for NonePolicy
-		panic("unknown policy")
+	default:
+		return 0
 	}
 }
 
-type newDeviceFunc func(originDevice smi.Device, isDisabled bool) ([]FuriosaDevice, error)
-
-func newDeviceFuncResolver(policy PartitioningPolicy) (ret newDeviceFunc) {
-	// Note: config validation ensure that there is no exception other than listed strategies.
-	switch policy {
-	case NonePolicy:
-		ret = func(originDevice smi.Device, isDisabled bool) ([]FuriosaDevice, error) {
-			newExclusiveDevice, err := newExclusiveDevice(originDevice, isDisabled)
-			if err != nil {
-				return nil, err
-			}
-
-			return []FuriosaDevice{newExclusiveDevice}, nil
-		}
+// Validate checks whether the policy is one of the predefined policies, or a partition layout such as "4+2+1+1" or "0-3,4-5,6,7".
+func (strategy PartitioningPolicy) Validate() error {
+	if strategy == NonePolicy || strategy.CoreSize() > 0 {
+		return nil
+	}
 
-	case SingleCorePolicy, DualCorePolicy, QuadCorePolicy:
-		ret = func(originDevice smi.Device, isDisabled bool) ([]FuriosaDevice, error) {
-			deviceInfo, err := originDevice.DeviceInfo()
-			if err != nil {
-				return nil, err
-			}
+	_, err := ParsePartitionLayout(string(strategy))
 
-			numOfCoresPerPartition := policy.CoreSize()
-			totalCores := int(deviceInfo.CoreNum())
-			newPartitionedDevices, err := newPartitionedDevices(originDevice, numOfCoresPerPartition, totalCores/numOfCoresPerPartition, isDisabled)
-			if err != nil {
-				return nil, err
-			}
+	return err
+}
 
-			return newPartitionedDevices, nil
-		}
+// Layout returns the PartitionLayout of a card with coreNum cores. It returns nil for NonePolicy, which doesn't partition cards.
+func (strategy PartitioningPolicy) Layout(coreNum int) (PartitionLayout, error) {
+	if strategy == NonePolicy {
+		return nil, nil
+	}
 
-	default:
-		panic("unknown policy")
+	if coreSize := strategy.CoreSize(); coreSize > 0 {
+		return newUniformPartitionLayout(coreSize, coreNum), nil
 	}
 
-	return ret
+	return ParsePartitionLayout(string(strategy))
 }
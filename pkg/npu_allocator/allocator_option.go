@@ -0,0 +1,34 @@
+package npu_allocator
+
+import (
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+)
+
+// AllocatorOption configures NpuAllocator built from list of smi.Device.
+type AllocatorOption func(*allocatorOptions)
+
+type allocatorOptions struct {
+	scoringPolicy TopologyScoringPolicy
+
+	thermalDevices []smi.Device
+	thermalPolicy  ThermalPolicy
+}
+
+func newAllocatorOptions(opts ...AllocatorOption) *allocatorOptions {
+	options := &allocatorOptions{
+		scoringPolicy: DefaultTopologyScoringPolicy(),
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+// WithTopologyScoringPolicy overrides TopologyScoringPolicy used to build TopologyHintMatrix.
+func WithTopologyScoringPolicy(scoringPolicy TopologyScoringPolicy) AllocatorOption {
+	return func(o *allocatorOptions) {
+		o.scoringPolicy = scoringPolicy
+	}
+}
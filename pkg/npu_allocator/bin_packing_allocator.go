@@ -10,19 +10,32 @@ var _ NpuAllocator = (*binPackingNpuAllocator)(nil)
 
 type binPackingNpuAllocator struct {
 	topologyScoreCalculator TopologyScoreCalculator
+	thermalTieBreaker       *thermalTieBreaker
 }
 
-func NewBinPackingNpuAllocator(devices []smi.Device) (NpuAllocator, error) {
-	topologyHintMatrix, err := NewTopologyHintMatrix(devices)
+func NewBinPackingNpuAllocator(devices []smi.Device, opts ...AllocatorOption) (NpuAllocator, error) {
+	options := newAllocatorOptions(opts...)
+
+	topologyHintMatrix, err := NewTopologyHintMatrixWithScoringPolicy(devices, options.scoringPolicy)
 	if err != nil {
 		return nil, err
 	}
 
-	return newBinPackingNpuAllocator(generateTopologyScoreCalculator(topologyHintMatrix)), nil
+	tieBreaker, err := newThermalTieBreaker(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBinPackingNpuAllocator(generateTopologyScoreCalculator(topologyHintMatrix), tieBreaker), nil
 }
 
-func NewMockBinPackingNpuAllocator(topologyHintMatrix TopologyHintMatrix) (NpuAllocator, error) {
-	return newBinPackingNpuAllocator(generateTopologyScoreCalculator(topologyHintMatrix)), nil
+func NewMockBinPackingNpuAllocator(topologyHintMatrix TopologyHintMatrix, opts ...AllocatorOption) (NpuAllocator, error) {
+	tieBreaker, err := newThermalTieBreaker(newAllocatorOptions(opts...))
+	if err != nil {
+		return nil, err
+	}
+
+	return newBinPackingNpuAllocator(generateTopologyScoreCalculator(topologyHintMatrix), tieBreaker), nil
 }
 
 // generateTopologyScoreCalculator returns calculator that calculates total sum of given TopologyHintKey list.
@@ -57,8 +70,11 @@ func generateTopologyScoreCalculator(topologyHintMatrix TopologyHintMatrix) Topo
 	}
 }
 
-func newBinPackingNpuAllocator(topologyScoreCalculator TopologyScoreCalculator) NpuAllocator {
-	return &binPackingNpuAllocator{topologyScoreCalculator: topologyScoreCalculator}
+func newBinPackingNpuAllocator(topologyScoreCalculator TopologyScoreCalculator, tieBreaker *thermalTieBreaker) NpuAllocator {
+	return &binPackingNpuAllocator{
+		topologyScoreCalculator: topologyScoreCalculator,
+		thermalTieBreaker:       tieBreaker,
+	}
 }
 
 func (b *binPackingNpuAllocator) Allocate(available DeviceSet, required DeviceSet, size int) DeviceSet {
@@ -67,22 +83,14 @@ func (b *binPackingNpuAllocator) Allocate(available DeviceSet, required DeviceSe
 		return required
 	}
 
-	// Step 1: build a map with PCIBusID as a key to access available DeviceSet
+	// Step 1: build a map with PCIBusID as a key to access available DeviceSet, excluding required devices.
 	availableDevicesByHintKeyMap := util.NewBtreeMapWithLessFunc[TopologyHintKey, DeviceSet](available.Len(), func(a, b util.BtreeMapItem[TopologyHintKey, DeviceSet]) bool {
 		key1, key2 := a.Key, b.Key
 
 		return key1 < key2
 	})
 
-	for _, device := range available.Devices() {
-		hintKey := device.TopologyHintKey()
-
-		ds := availableDevicesByHintKeyMap.Get(hintKey)
-		if ds == nil {
-			ds = NewDeviceSet()
-		}
-
-		ds.Insert(device)
+	for hintKey, ds := range available.Difference(required.Devices()...).GroupByTopologyHintKey() {
 		availableDevicesByHintKeyMap.Insert(hintKey, ds)
 	}
 
@@ -92,34 +100,30 @@ func (b *binPackingNpuAllocator) Allocate(available DeviceSet, required DeviceSe
 		return a < b
 	})
 
-	for _, device := range required.Devices() {
+	for device := range required.All() {
 		collectedDevices.Insert(device)
-
-		hintKey := device.TopologyHintKey()
-		requiredHintKeySet.Insert(hintKey)
-
-		ds := availableDevicesByHintKeyMap.Get(hintKey)
-		ds = ds.Difference(device)
-		availableDevicesByHintKeyMap.Insert(hintKey, ds)
+		requiredHintKeySet.Insert(device.TopologyHintKey())
 	}
 
 	if collectedDevices.Len() == size {
 		return collectedDevices
 	}
 
-	// Step 3: Consume required keys first to mitigate fragmentation.
-	for _, hintKey := range requiredHintKeySet.Items() {
-		for _, device := range availableDevicesByHintKeyMap.Get(hintKey).Devices() {
-			collectedDevices.Insert(device)
-
-			ds := availableDevicesByHintKeyMap.Get(hintKey)
-			ds = ds.Difference(device)
-			availableDevicesByHintKeyMap.Insert(hintKey, ds)
+	// Step 3: Consume required keys first to mitigate fragmentation. Consumed keys are removed from the map,
+	// so the map only holds unused keys afterward.
+	for hintKey := range requiredHintKeySet.All() {
+		if ds := availableDevicesByHintKeyMap.Get(hintKey); ds != nil {
+			collectedOnHintKey := collectedDevices.Filter(func(device Device) bool {
+				return device.TopologyHintKey() == hintKey
+			})
 
+			collectedDevices.Insert(selectAdjacentDevices(ds, collectedOnHintKey.Devices(), size-collectedDevices.Len())...)
 			if collectedDevices.Len() == size {
 				return collectedDevices
 			}
 		}
+
+		availableDevicesByHintKeyMap.Delete(hintKey)
 	}
 
 	// Step 4: Calculate device count to be allocated.
@@ -127,12 +131,8 @@ func (b *binPackingNpuAllocator) Allocate(available DeviceSet, required DeviceSe
 
 	unusedHintKeys := make([]TopologyHintKey, 0)
 	deviceCountByHintKeyMap := make(map[TopologyHintKey]int)
-	for _, hintKey := range availableDevicesByHintKeyMap.Keys() {
-		devices := availableDevicesByHintKeyMap.Get(hintKey)
-		if !requiredHintKeySet.Has(hintKey) {
-			unusedHintKeys = append(unusedHintKeys, hintKey)
-		}
-
+	for hintKey, devices := range availableDevicesByHintKeyMap.All() {
+		unusedHintKeys = append(unusedHintKeys, hintKey)
 		deviceCountByHintKeyMap[hintKey] = devices.Len()
 	}
 
@@ -148,26 +148,36 @@ func (b *binPackingNpuAllocator) Allocate(available DeviceSet, required DeviceSe
 	}
 
 	// Step 7: Score each combination and find the one with the highest score.
+	// Ties are broken by the lowest thermal penalty if the thermal tie breaker is enabled.
+	thermalPenaltyCalculator := b.thermalTieBreaker.snapshot()
+
 	var highestScore *uint = nil
+	var lowestPenalty float64
 	var bestHintKeys []TopologyHintKey
 	for _, hintKeys := range validCombinationsOfHintKeys {
 		score := b.topologyScoreCalculator(hintKeys)
 
 		if highestScore == nil || score > *highestScore {
 			highestScore = &score
+			lowestPenalty = thermalPenaltyCalculator(hintKeys)
 			bestHintKeys = hintKeys
+		} else if score == *highestScore && b.thermalTieBreaker != nil {
+			if penalty := thermalPenaltyCalculator(hintKeys); penalty < lowestPenalty {
+				lowestPenalty = penalty
+				bestHintKeys = hintKeys
+			}
 		}
 	}
 
-	// Step 8: Add to collectedDevices and return.
-BestHintKeysLoop:
+	// Step 8: Add to collectedDevices and return. Within a key, adjacent core ranges are preferred.
+	// Required keys were removed from the map in Step 3, and `required` may already exceed `size`.
 	for _, hintKey := range bestHintKeys {
-		for _, device := range availableDevicesByHintKeyMap.Get(hintKey).Devices() {
-			collectedDevices.Insert(device)
+		if collectedDevices.Len() >= size {
+			break
+		}
 
-			if collectedDevices.Len() == size {
-				break BestHintKeysLoop
-			}
+		if ds := availableDevicesByHintKeyMap.Get(hintKey); ds != nil {
+			collectedDevices.Insert(selectAdjacentDevices(ds, nil, size-collectedDevices.Len())...)
 		}
 	}
 
@@ -766,3 +766,19 @@ func TestBinPackingNpuAllocator(t *testing.T) {
 		}
 	})
 }
+
+func TestBinPackingNpuAllocatorWithOversizedRequired(t *testing.T) {
+	sut, err := NewMockBinPackingNpuAllocator(buildStaticHintMatrixForTwoSocketBalancedConfig())
+	assert.NoError(t, err)
+
+	device0 := NewMockDevice(0, "0", "0")
+	device1 := NewMockDevice(1, "1", "1")
+	device2 := NewMockDevice(1, "2", "1")
+
+	required := NewDeviceSet(device1, device2)
+
+	assert.NotPanics(t, func() {
+		actual := sut.Allocate(NewDeviceSet(device0, device1, device2), required, 1)
+		assert.True(t, required.Equal(actual.Devices()...))
+	})
+}
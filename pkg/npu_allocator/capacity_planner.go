@@ -0,0 +1,232 @@
+package npu_allocator
+
+import (
+	"fmt"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+)
+
+var _ CapacityPlanner = (*capacityPlanner)(nil)
+
+// CapacityPlanner answers capacity queries over available devices and the topology.
+// The score of a device set is the sum of TopologyHintMatrix scores of every pair of devices, same as scoreBasedOptimalNpuAllocator.
+type CapacityPlanner interface {
+	// Score returns the score of the given devices.
+	Score(devices DeviceSet) uint
+
+	// BestAchievableScore returns the highest score of `size` devices from available devices.
+	// It returns false if there are not enough available devices.
+	BestAchievableScore(available DeviceSet, size int) (uint, bool)
+
+	// MaxDisjointSets returns the maximum number of disjoint sets of `size` devices with score of at least minScore
+	// which can be taken from available devices at the same time.
+	MaxDisjointSets(available DeviceSet, size int, minScore uint) int
+
+	// FragmentationIndex returns 1 - (the largest number of available devices in a card / the number of available devices).
+	// It is 0 if every available device is in a single card or nothing is available, and approaches 1 as devices are scattered.
+	FragmentationIndex(available DeviceSet) float64
+}
+
+type capacityPlanner struct {
+	topologyHintMatrix TopologyHintMatrix
+}
+
+func NewCapacityPlanner(devices []smi.Device, opts ...AllocatorOption) (CapacityPlanner, error) {
+	options := newAllocatorOptions(opts...)
+
+	topologyHintMatrix, err := NewTopologyHintMatrixWithScoringPolicy(devices, options.scoringPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCapacityPlanner(topologyHintMatrix), nil
+}
+
+func NewMockCapacityPlanner(topologyHintMatrix TopologyHintMatrix) (CapacityPlanner, error) {
+	return newCapacityPlanner(topologyHintMatrix), nil
+}
+
+func newCapacityPlanner(topologyHintMatrix TopologyHintMatrix) CapacityPlanner {
+	return &capacityPlanner{topologyHintMatrix: topologyHintMatrix}
+}
+
+// countByHintKey returns sorted keys of available devices and the number of available devices per key.
+func countByHintKey(available DeviceSet) ([]TopologyHintKey, []int) {
+	keys := uniqueHintKeys(available.Devices())
+	indices := make(map[TopologyHintKey]int, len(keys))
+	for idx, key := range keys {
+		indices[key] = idx
+	}
+
+	counts := make([]int, len(keys))
+	for _, device := range available.Devices() {
+		counts[indices[device.TopologyHintKey()]]++
+	}
+
+	return keys, counts
+}
+
+func (c *capacityPlanner) pairScore(key1, key2 TopologyHintKey) uint {
+	if key1 > key2 {
+		key1, key2 = key2, key1
+	}
+
+	return c.topologyHintMatrix[key1][key2]
+}
+
+// forEachCounts visits every way to take `size` devices, as the number of devices to take per key, with its score.
+// The score only depends on the number of devices per key, so it enumerates counts instead of device combinations.
+// The slice passed to visit is reused, so visit must copy it to keep it.
+func (c *capacityPlanner) forEachCounts(keys []TopologyHintKey, available []int, size int, visit func(counts []int, score uint)) {
+	// suffix[i] is the number of available devices of keys[i:], used to prune infeasible branches.
+	suffix := make([]int, len(keys)+1)
+	for i := len(keys) - 1; i >= 0; i-- {
+		suffix[i] = suffix[i+1] + available[i]
+	}
+
+	current := make([]int, len(keys))
+
+	var search func(idx int, remaining int, score uint)
+	search = func(idx int, remaining int, score uint) {
+		if remaining == 0 {
+			visit(current, score)
+			return
+		}
+
+		if idx == len(keys) || suffix[idx] < remaining {
+			return
+		}
+
+		for count := min(available[idx], remaining); count >= 0; count-- {
+			added := uint(count*(count-1)/2) * c.pairScore(keys[idx], keys[idx])
+			for prev := 0; prev < idx; prev++ {
+				added += uint(count*current[prev]) * c.pairScore(keys[prev], keys[idx])
+			}
+
+			current[idx] = count
+			search(idx+1, remaining-count, score+added)
+		}
+
+		current[idx] = 0
+	}
+
+	search(0, size, 0)
+}
+
+// bestCounts searches the number of devices to take per key maximizing the score.
+func (c *capacityPlanner) bestCounts(keys []TopologyHintKey, available []int, size int) ([]int, uint, bool) {
+	total := 0
+	for _, count := range available {
+		total += count
+	}
+
+	if size <= 0 || total < size {
+		return nil, 0, false
+	}
+
+	var best []int
+	var bestScore uint
+	c.forEachCounts(keys, available, size, func(counts []int, score uint) {
+		if best == nil || score > bestScore {
+			best = append([]int(nil), counts...)
+			bestScore = score
+		}
+	})
+
+	return best, bestScore, true
+}
+
+func (c *capacityPlanner) Score(devices DeviceSet) uint {
+	score := uint(0)
+	list := devices.Devices()
+	for i := range list {
+		for j := i + 1; j < len(list); j++ {
+			score += c.pairScore(list[i].TopologyHintKey(), list[j].TopologyHintKey())
+		}
+	}
+
+	return score
+}
+
+func (c *capacityPlanner) BestAchievableScore(available DeviceSet, size int) (uint, bool) {
+	keys, counts := countByHintKey(available)
+	_, score, ok := c.bestCounts(keys, counts, size)
+
+	return score, ok
+}
+
+func (c *capacityPlanner) MaxDisjointSets(available DeviceSet, size int, minScore uint) int {
+	keys, counts := countByHintKey(available)
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	if size <= 0 || total < size {
+		return 0
+	}
+
+	// candidates are the ways to take a set with score of at least minScore.
+	candidates := make([][]int, 0)
+	c.forEachCounts(keys, counts, size, func(candidate []int, score uint) {
+		if score >= minScore {
+			candidates = append(candidates, append([]int(nil), candidate...))
+		}
+	})
+
+	// maxSets returns the maximum number of sets taken from remaining devices, using candidates from candidates[from:]
+	// only, so the same combination of sets is not visited in different orders.
+	memo := make(map[string]int)
+	var maxSets func(remaining []int, from int) int
+	maxSets = func(remaining []int, from int) int {
+		memoKey := fmt.Sprint(remaining, from)
+		if sets, ok := memo[memoKey]; ok {
+			return sets
+		}
+
+		left := 0
+		for _, count := range remaining {
+			left += count
+		}
+
+		best := 0
+		for idx := from; idx < len(candidates) && best < left/size; idx++ {
+			next := make([]int, len(remaining))
+			fits := true
+			for key := range remaining {
+				next[key] = remaining[key] - candidates[idx][key]
+				if next[key] < 0 {
+					fits = false
+					break
+				}
+			}
+
+			if fits {
+				best = max(best, 1+maxSets(next, idx))
+			}
+		}
+
+		memo[memoKey] = best
+
+		return best
+	}
+
+	return maxSets(counts, 0)
+}
+
+func (c *capacityPlanner) FragmentationIndex(available DeviceSet) float64 {
+	_, counts := countByHintKey(available)
+
+	total, largest := 0, 0
+	for _, count := range counts {
+		total += count
+		largest = max(largest, count)
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return 1 - float64(largest)/float64(total)
+}
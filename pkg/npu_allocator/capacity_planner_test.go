@@ -0,0 +1,154 @@
+package npu_allocator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapacityPlanner(t *testing.T) {
+	sut, err := NewMockCapacityPlanner(buildStaticHintMatrixForTwoSocketBalancedConfig())
+	assert.NoError(t, err)
+
+	partitioned := generateSameBoardMockDeviceSet(0, 4, "0").Union(generateSameBoardMockDeviceSet(1, 4, "1").Devices()...)
+	fragmented := generateSameBoardMockDeviceSet(0, 4, "0").Union(generateSameBoardMockDeviceSet(4, 2, "4").Devices()...)
+
+	t.Run("Score", func(t *testing.T) {
+		assert.Equal(t, uint(0), sut.Score(NewDeviceSet()))
+		assert.Equal(t, uint(30), sut.Score(buildMockDeviceSet(0, 1)))
+		assert.Equal(t, uint(140), sut.Score(buildMockDeviceSet(0, 3)))
+		assert.Equal(t, uint(70), sut.Score(generateSameBoardMockDeviceSet(0, 2, "0")))
+	})
+
+	t.Run("BestAchievableScore", func(t *testing.T) {
+		tests := []struct {
+			description string
+			available   DeviceSet
+			size        int
+			expected    uint
+			expectedOk  bool
+		}{
+			{
+				description: "two devices under the same host bridge",
+				available:   buildMockDeviceSet(0, 7),
+				size:        2,
+				expected:    30,
+				expectedOk:  true,
+			},
+			{
+				description: "four devices in the same socket",
+				available:   buildMockDeviceSet(0, 7),
+				size:        4,
+				expected:    140,
+				expectedOk:  true,
+			},
+			{
+				description: "two partitions of the same card",
+				available:   partitioned,
+				size:        2,
+				expected:    70,
+				expectedOk:  true,
+			},
+			{
+				description: "single device has no pair",
+				available:   buildMockDeviceSet(0, 7),
+				size:        1,
+				expected:    0,
+				expectedOk:  true,
+			},
+			{
+				description: "not enough devices",
+				available:   buildMockDeviceSet(0, 3),
+				size:        5,
+				expected:    0,
+				expectedOk:  false,
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.description, func(t *testing.T) {
+				actual, ok := sut.BestAchievableScore(tc.available, tc.size)
+				assert.Equal(t, tc.expectedOk, ok)
+				assert.Equal(t, tc.expected, actual)
+			})
+		}
+	})
+
+	t.Run("MaxDisjointSets", func(t *testing.T) {
+		tests := []struct {
+			description string
+			available   DeviceSet
+			size        int
+			minScore    uint
+			expected    int
+		}{
+			{
+				description: "pairs under the same host bridge",
+				available:   buildMockDeviceSet(0, 7),
+				size:        2,
+				minScore:    30,
+				expected:    4,
+			},
+			{
+				description: "pairs under the same host bridge with a device taken",
+				available:   buildMockDeviceSet(1, 7),
+				size:        2,
+				minScore:    30,
+				expected:    3,
+			},
+			{
+				description: "minimum score is not achievable",
+				available:   buildMockDeviceSet(0, 7),
+				size:        2,
+				minScore:    31,
+				expected:    0,
+			},
+			{
+				description: "without locality requirement",
+				available:   buildMockDeviceSet(0, 7),
+				size:        3,
+				minScore:    0,
+				expected:    2,
+			},
+			{
+				description: "partitions of the same card",
+				available:   partitioned,
+				size:        2,
+				minScore:    70,
+				expected:    4,
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.description, func(t *testing.T) {
+				assert.Equal(t, tc.expected, sut.MaxDisjointSets(tc.available, tc.size, tc.minScore))
+			})
+		}
+
+		t.Run("greedy choice of the best set is not optimal", func(t *testing.T) {
+			// taking the best set {A, A} leaves {B, C} below the minimum score, while {A, B} and {A, C} both qualify.
+			planner, err := NewMockCapacityPlanner(TopologyHintMatrix{
+				"A": {"A": 70, "B": 30, "C": 30},
+				"B": {"B": 70, "C": 10},
+				"C": {"C": 70},
+			})
+			assert.NoError(t, err)
+
+			available := NewDeviceSet(
+				NewMockDevice(0, "0", "A"),
+				NewMockDevice(1, "1", "A"),
+				NewMockDevice(2, "2", "B"),
+				NewMockDevice(3, "3", "C"),
+			)
+
+			assert.Equal(t, 2, planner.MaxDisjointSets(available, 2, 30))
+		})
+	})
+
+	t.Run("FragmentationIndex", func(t *testing.T) {
+		assert.Equal(t, 0.0, sut.FragmentationIndex(NewDeviceSet()))
+		assert.Equal(t, 0.0, sut.FragmentationIndex(generateSameBoardMockDeviceSet(0, 4, "0")))
+		assert.InDelta(t, 0.875, sut.FragmentationIndex(buildMockDeviceSet(0, 7)), 1e-9)
+		assert.InDelta(t, 1.0/3.0, sut.FragmentationIndex(fragmented), 1e-9)
+	})
+}
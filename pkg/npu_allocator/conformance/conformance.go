@@ -0,0 +1,208 @@
+package conformance
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/npu_allocator"
+)
+
+const (
+	defaultRandomCases = 100
+	defaultSeed        = 1
+)
+
+// AllocatorFactory builds the NpuAllocator under test for the given topology.
+type AllocatorFactory func(topology npu_allocator.MockTopology) (npu_allocator.NpuAllocator, error)
+
+type Option func(*options)
+
+type options struct {
+	topologies  []npu_allocator.MockTopology
+	randomCases int
+	seed        int64
+}
+
+// WithTopologies replaces the default mock topologies from npu_allocator.MockTopologies.
+func WithTopologies(topologies ...npu_allocator.MockTopology) Option {
+	return func(o *options) {
+		o.topologies = topologies
+	}
+}
+
+// WithRandomCases sets the number of random cases per topology.
+func WithRandomCases(randomCases int) Option {
+	return func(o *options) {
+		o.randomCases = randomCases
+	}
+}
+
+// WithSeed sets the seed of random cases, so that a failure can be reproduced.
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.seed = seed
+	}
+}
+
+// Case is a single allocation request.
+type Case struct {
+	Description string
+	Available   npu_allocator.DeviceSet
+	Required    npu_allocator.DeviceSet
+	Size        int
+}
+
+func (c Case) feasible() bool {
+	return c.Size >= c.Required.Len() && c.Size <= c.Available.Len() &&
+		(c.Required.Len() == 0 || c.Available.Contains(c.Required.Devices()...))
+}
+
+// Run checks that the allocator built by the factory satisfies the contract of NpuAllocator for every topology.
+//
+// For a feasible request, the result must have exactly the requested size, contain the required devices,
+// be a subset of the available devices, and be the same across repeated calls.
+// For any request, including infeasible ones, Allocate must not panic.
+func Run(t *testing.T, factory AllocatorFactory, opts ...Option) {
+	o := &options{
+		topologies:  npu_allocator.MockTopologies(),
+		randomCases: defaultRandomCases,
+		seed:        defaultSeed,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	for _, topology := range o.topologies {
+		t.Run(topology.Name, func(t *testing.T) {
+			allocator, err := factory(topology)
+			if err != nil {
+				t.Fatalf("couldn't build allocator: %v", err)
+			}
+
+			for _, c := range EdgeCases(topology) {
+				t.Run(c.Description, func(t *testing.T) {
+					Check(t, allocator, c)
+				})
+			}
+
+			for _, c := range RandomCases(topology, o.randomCases, o.seed) {
+				t.Run(c.Description, func(t *testing.T) {
+					Check(t, allocator, c)
+				})
+			}
+		})
+	}
+}
+
+// Check runs a single case against the allocator and reports violated invariants.
+func Check(t *testing.T, allocator npu_allocator.NpuAllocator, c Case) {
+	t.Helper()
+
+	actual, panicked := allocate(allocator, c)
+	if panicked != nil {
+		t.Fatalf("Allocate panicked: %v", panicked)
+	}
+
+	if !c.feasible() {
+		return
+	}
+
+	if actual == nil {
+		t.Fatalf("Allocate returned nil for a feasible request")
+	}
+
+	if actual.Len() != c.Size {
+		t.Errorf("expected %d devices but got %d: %s", c.Size, actual.Len(), deviceIDs(actual))
+	}
+
+	if c.Required.Len() > 0 && !actual.Contains(c.Required.Devices()...) {
+		t.Errorf("result %s doesn't contain required devices %s", deviceIDs(actual), deviceIDs(c.Required))
+	}
+
+	if outside := actual.Difference(c.Available.Devices()...); outside.Len() > 0 {
+		t.Errorf("result contains devices %s which are not available", deviceIDs(outside))
+	}
+
+	again, panicked := allocate(allocator, c)
+	if panicked != nil {
+		t.Fatalf("Allocate panicked on the second call: %v", panicked)
+	}
+
+	if !actual.Equal(again.Devices()...) {
+		t.Errorf("result is not deterministic: %s and %s", deviceIDs(actual), deviceIDs(again))
+	}
+}
+
+func allocate(allocator npu_allocator.NpuAllocator, c Case) (result npu_allocator.DeviceSet, panicked any) {
+	defer func() {
+		panicked = recover()
+	}()
+
+	// pass copies, so that an allocator modifying its arguments doesn't affect the checks.
+	return allocator.Allocate(npu_allocator.NewDeviceSet(c.Available.Devices()...), npu_allocator.NewDeviceSet(c.Required.Devices()...), c.Size), nil
+}
+
+// EdgeCases returns boundary requests of the topology.
+func EdgeCases(topology npu_allocator.MockTopology) []Case {
+	all := topology.Devices
+	devices := all.Devices()
+	empty := npu_allocator.NewDeviceSet()
+
+	return []Case{
+		{Description: "empty request", Available: all, Required: empty, Size: 0},
+		{Description: "single device", Available: all, Required: empty, Size: 1},
+		{Description: "every device", Available: all, Required: empty, Size: all.Len()},
+		{Description: "required only", Available: all, Required: npu_allocator.NewDeviceSet(devices[0]), Size: 1},
+		{Description: "every device with required", Available: all, Required: npu_allocator.NewDeviceSet(devices[len(devices)-1]), Size: all.Len()},
+		{Description: "nothing available", Available: empty, Required: empty, Size: 1},
+		{Description: "more than available", Available: all, Required: empty, Size: all.Len() + 1},
+	}
+}
+
+// RandomCases returns feasible requests over random subsets of devices of the topology.
+func RandomCases(topology npu_allocator.MockTopology, count int, seed int64) []Case {
+	random := rand.New(rand.NewSource(seed))
+	devices := topology.Devices.Devices()
+
+	cases := make([]Case, 0, count)
+	for i := 0; i < count; i++ {
+		available := npu_allocator.NewDeviceSet()
+		for _, device := range devices {
+			if random.Intn(4) != 0 {
+				available.Insert(device)
+			}
+		}
+
+		if available.Len() == 0 {
+			available.Insert(devices[random.Intn(len(devices))])
+		}
+
+		availableDevices := available.Devices()
+		random.Shuffle(len(availableDevices), func(i, j int) {
+			availableDevices[i], availableDevices[j] = availableDevices[j], availableDevices[i]
+		})
+
+		required := npu_allocator.NewDeviceSet(availableDevices[:random.Intn(min(3, len(availableDevices))+1)]...)
+		size := required.Len() + random.Intn(available.Len()-required.Len()+1)
+
+		cases = append(cases, Case{
+			Description: fmt.Sprintf("random case %d (seed %d)", i, seed),
+			Available:   available,
+			Required:    required,
+			Size:        size,
+		})
+	}
+
+	return cases
+}
+
+func deviceIDs(ds npu_allocator.DeviceSet) []string {
+	ids := make([]string, 0, ds.Len())
+	for device := range ds.All() {
+		ids = append(ids, device.ID())
+	}
+
+	return ids
+}
@@ -0,0 +1,39 @@
+package conformance
+
+import (
+	"testing"
+
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/npu_allocator"
+)
+
+func TestConformance(t *testing.T) {
+	tests := []struct {
+		description string
+		factory     AllocatorFactory
+	}{
+		{
+			description: "bin packing allocator",
+			factory: func(topology npu_allocator.MockTopology) (npu_allocator.NpuAllocator, error) {
+				return npu_allocator.NewMockBinPackingNpuAllocator(topology.TopologyHintMatrix)
+			},
+		},
+		{
+			description: "score based optimal allocator",
+			factory: func(topology npu_allocator.MockTopology) (npu_allocator.NpuAllocator, error) {
+				return npu_allocator.NewMockScoreBasedOptimalNpuAllocator(topology.TopologyHintProvider())
+			},
+		},
+		{
+			description: "spread allocator",
+			factory: func(topology npu_allocator.MockTopology) (npu_allocator.NpuAllocator, error) {
+				return npu_allocator.NewMockSpreadNpuAllocator(topology.TopologyHintProvider())
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			Run(t, tc.factory)
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package npu_allocator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/util"
+	"gonum.org/v1/gonum/stat/combin"
+)
+
+// TopologyConstraint is a hard constraint that every allocated device must satisfy together.
+type TopologyConstraint string
+
+const (
+	// SameNUMANodeConstraint requires all devices to be attached to the same NUMA node.
+	SameNUMANodeConstraint TopologyConstraint = "same-numa-node"
+	// SameHostBridgeConstraint requires all devices to be placed under the same host bridge or PCIe switch.
+	SameHostBridgeConstraint TopologyConstraint = "same-host-bridge"
+	// P2PAccessibleConstraint requires all devices to be p2p accessible each other.
+	P2PAccessibleConstraint TopologyConstraint = "p2p-accessible"
+)
+
+// UnsatisfiableConstraintError is returned when no device set satisfies the given TopologyConstraints.
+type UnsatisfiableConstraintError struct {
+	Constraints []TopologyConstraint
+	Size        int
+}
+
+func (e *UnsatisfiableConstraintError) Error() string {
+	constraints := make([]string, 0, len(e.Constraints))
+	for _, constraint := range e.Constraints {
+		constraints = append(constraints, string(constraint))
+	}
+
+	return fmt.Sprintf("couldn't find %d devices satisfying constraints [%s]", e.Size, strings.Join(constraints, ", "))
+}
+
+// ConstrainedNpuAllocator is a NpuAllocator enforcing TopologyConstraints.
+type ConstrainedNpuAllocator interface {
+	NpuAllocator
+
+	// TryAllocate returns UnsatisfiableConstraintError if there is no device set satisfying constraints.
+	TryAllocate(available DeviceSet, required DeviceSet, size int) (DeviceSet, error)
+}
+
+// topologyConstraintChecker holds topology information of physical cards to evaluate TopologyConstraints.
+type topologyConstraintChecker struct {
+	numaNodes     map[TopologyHintKey]int
+	linkTypes     map[TopologyHintKey]map[TopologyHintKey]smi.LinkType
+	p2pAccessible map[TopologyHintKey]map[TopologyHintKey]bool
+}
+
+func newTopologyConstraintChecker(smiDevices []smi.Device) (*topologyConstraintChecker, error) {
+	checker := &topologyConstraintChecker{
+		numaNodes:     make(map[TopologyHintKey]int),
+		linkTypes:     make(map[TopologyHintKey]map[TopologyHintKey]smi.LinkType),
+		p2pAccessible: make(map[TopologyHintKey]map[TopologyHintKey]bool),
+	}
+
+	hintKeys := make(map[smi.Device]TopologyHintKey)
+	for _, device := range smiDevices {
+		deviceInfo, err := device.DeviceInfo()
+		if err != nil {
+			return nil, err
+		}
+
+		pciBusID, err := util.ParseBusIDFromBDF(deviceInfo.BDF())
+		if err != nil {
+			return nil, err
+		}
+
+		hintKey := TopologyHintKey(pciBusID)
+		hintKeys[device] = hintKey
+		checker.numaNodes[hintKey] = int(deviceInfo.NumaNode())
+		checker.linkTypes[hintKey] = make(map[TopologyHintKey]smi.LinkType)
+		checker.p2pAccessible[hintKey] = make(map[TopologyHintKey]bool)
+	}
+
+	for device1, key1 := range hintKeys {
+		for device2, key2 := range hintKeys {
+			linkType, err := device1.DeviceToDeviceLinkType(device2)
+			if err != nil {
+				return nil, err
+			}
+
+			p2pAccessible, err := device1.P2PAccessible(device2)
+			if err != nil {
+				return nil, err
+			}
+
+			checker.linkTypes[key1][key2] = linkType
+			checker.p2pAccessible[key1][key2] = p2pAccessible
+		}
+	}
+
+	return checker, nil
+}
+
+// satisfies checks whether the given keys satisfy the constraint. Unknown keys never satisfy any constraint.
+func (c *topologyConstraintChecker) satisfies(constraint TopologyConstraint, keys []TopologyHintKey) bool {
+	for _, key := range keys {
+		if _, exists := c.numaNodes[key]; !exists {
+			return false
+		}
+	}
+
+	switch constraint {
+	case SameNUMANodeConstraint:
+		for _, key := range keys {
+			if c.numaNodes[key] != c.numaNodes[keys[0]] {
+				return false
+			}
+		}
+
+	case SameHostBridgeConstraint:
+		for _, key1 := range keys {
+			for _, key2 := range keys {
+				if key1 != key2 && c.linkTypes[key1][key2] < smi.LinkTypeHostBridge {
+					return false
+				}
+			}
+		}
+
+	case P2PAccessibleConstraint:
+		for _, key1 := range keys {
+			for _, key2 := range keys {
+				if key1 != key2 && !c.p2pAccessible[key1][key2] {
+					return false
+				}
+			}
+		}
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+var _ ConstrainedNpuAllocator = (*constrainedNpuAllocator)(nil)
+
+type constrainedNpuAllocator struct {
+	allocator               NpuAllocator
+	checker                 *topologyConstraintChecker
+	topologyScoreCalculator TopologyScoreCalculator
+	constraints             []TopologyConstraint
+}
+
+// NewConstrainedNpuAllocator wraps the given NpuAllocator to enforce TopologyConstraints.
+// The wrapped allocator is tried first, and if its result violates constraints,
+// card combinations satisfying constraints are searched from the fewest number of cards.
+func NewConstrainedNpuAllocator(devices []smi.Device, allocator NpuAllocator, constraints ...TopologyConstraint) (ConstrainedNpuAllocator, error) {
+	return NewConstrainedNpuAllocatorWithOptions(devices, allocator, constraints)
+}
+
+// NewConstrainedNpuAllocatorWithOptions is NewConstrainedNpuAllocator accepting AllocatorOption.
+// TopologyScoringPolicy is used to rank card combinations satisfying constraints.
+func NewConstrainedNpuAllocatorWithOptions(devices []smi.Device, allocator NpuAllocator, constraints []TopologyConstraint, opts ...AllocatorOption) (ConstrainedNpuAllocator, error) {
+	for _, constraint := range constraints {
+		switch constraint {
+		case SameNUMANodeConstraint, SameHostBridgeConstraint, P2PAccessibleConstraint:
+		default:
+			return nil, fmt.Errorf("unknown topology constraint %q", constraint)
+		}
+	}
+
+	checker, err := newTopologyConstraintChecker(devices)
+	if err != nil {
+		return nil, err
+	}
+
+	topologyHintMatrix, err := NewTopologyHintMatrixWithScoringPolicy(devices, newAllocatorOptions(opts...).scoringPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &constrainedNpuAllocator{
+		allocator:               allocator,
+		checker:                 checker,
+		topologyScoreCalculator: generateTopologyScoreCalculator(topologyHintMatrix),
+		constraints:             constraints,
+	}, nil
+}
+
+func (c *constrainedNpuAllocator) satisfies(keys []TopologyHintKey) bool {
+	for _, constraint := range c.constraints {
+		if !c.checker.satisfies(constraint, keys) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// uniqueHintKeys returns sorted unique TopologyHintKeys of the given devices.
+func uniqueHintKeys(devices []Device) []TopologyHintKey {
+	hintKeySet := util.NewBtreeSetWithLessFunc[TopologyHintKey](len(devices), func(a, b TopologyHintKey) bool {
+		return a < b
+	})
+
+	for _, device := range devices {
+		hintKeySet.Insert(device.TopologyHintKey())
+	}
+
+	return hintKeySet.Items()
+}
+
+// Allocate returns empty DeviceSet if constraints cannot be satisfied.
+func (c *constrainedNpuAllocator) Allocate(available DeviceSet, required DeviceSet, size int) DeviceSet {
+	result, err := c.TryAllocate(available, required, size)
+	if err != nil {
+		return NewDeviceSet()
+	}
+
+	return result
+}
+
+func (c *constrainedNpuAllocator) TryAllocate(available DeviceSet, required DeviceSet, size int) (DeviceSet, error) {
+	unsatisfiableErr := &UnsatisfiableConstraintError{Constraints: c.constraints, Size: size}
+
+	requiredHintKeys := uniqueHintKeys(required.Devices())
+	if len(requiredHintKeys) > 0 && !c.satisfies(requiredHintKeys) {
+		return nil, unsatisfiableErr
+	}
+
+	if required.Len() > size || available.Len() < size {
+		return nil, unsatisfiableErr
+	}
+
+	// Step 1: try the wrapped allocator first.
+	if result := c.allocator.Allocate(available, required, size); result.Len() == size && c.satisfies(uniqueHintKeys(result.Devices())) {
+		return result, nil
+	}
+
+	// Step 2: count available devices per hint key, and collect keys compatible with required keys.
+	deviceCountByHintKey := make(map[TopologyHintKey]int)
+	for _, device := range available.Devices() {
+		deviceCountByHintKey[device.TopologyHintKey()] += 1
+	}
+
+	requiredHintKeySet := make(map[TopologyHintKey]struct{}, len(requiredHintKeys))
+	for _, hintKey := range requiredHintKeys {
+		requiredHintKeySet[hintKey] = struct{}{}
+	}
+
+	candidateHintKeys := make([]TopologyHintKey, 0)
+	for _, hintKey := range uniqueHintKeys(available.Devices()) {
+		if _, isRequired := requiredHintKeySet[hintKey]; isRequired {
+			continue
+		}
+
+		if c.satisfies(append([]TopologyHintKey{hintKey}, requiredHintKeys...)) {
+			candidateHintKeys = append(candidateHintKeys, hintKey)
+		}
+	}
+
+	// Step 3: search combinations from the fewest number of cards, and pick the best score among the smallest ones.
+	for k := 0; k <= len(candidateHintKeys); k++ {
+		var bestResult DeviceSet
+		var highestScore uint
+
+		for _, indices := range combin.Combinations(len(candidateHintKeys), k) {
+			hintKeys := append([]TopologyHintKey{}, requiredHintKeys...)
+			for _, idx := range indices {
+				hintKeys = append(hintKeys, candidateHintKeys[idx])
+			}
+
+			totalDevices := 0
+			for _, hintKey := range hintKeys {
+				totalDevices += deviceCountByHintKey[hintKey]
+			}
+
+			if totalDevices < size || len(hintKeys) == 0 || !c.satisfies(hintKeys) {
+				continue
+			}
+
+			hintKeySet := make(map[TopologyHintKey]struct{}, len(hintKeys))
+			for _, hintKey := range hintKeys {
+				hintKeySet[hintKey] = struct{}{}
+			}
+
+			restricted := NewDeviceSet()
+			for _, device := range available.Devices() {
+				if _, ok := hintKeySet[device.TopologyHintKey()]; ok {
+					restricted.Insert(device)
+				}
+			}
+
+			result := c.allocator.Allocate(restricted, required, size)
+			resultHintKeys := uniqueHintKeys(result.Devices())
+			if result.Len() != size || !c.satisfies(resultHintKeys) {
+				continue
+			}
+
+			if score := c.topologyScoreCalculator(resultHintKeys); bestResult == nil || score > highestScore {
+				bestResult = result
+				highestScore = score
+			}
+		}
+
+		if bestResult != nil {
+			return bestResult, nil
+		}
+	}
+
+	return nil, unsatisfiableErr
+}
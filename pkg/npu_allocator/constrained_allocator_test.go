@@ -0,0 +1,163 @@
+package npu_allocator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstrainedNpuAllocator(t *testing.T) {
+	smiDevices := smi.GetStaticMockDevices(smi.ArchRngd)
+
+	furiosaDevices, err := furiosa_device.NewFuriosaDevices(smiDevices, nil, furiosa_device.SingleCorePolicy)
+	assert.NoError(t, err)
+
+	// cores[i][j] is j-th core of i-th card.
+	cores := make([][]Device, len(smiDevices))
+	for idx, furiosaDevice := range furiosaDevices {
+		cardIdx := idx / (len(furiosaDevices) / len(smiDevices))
+		cores[cardIdx] = append(cores[cardIdx], NewDevice(furiosaDevice))
+	}
+
+	binPackingAllocator, err := NewBinPackingNpuAllocator(smiDevices)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		description string
+		constraints []TopologyConstraint
+		available   DeviceSet
+		required    DeviceSet
+		size        int
+		expected    DeviceSet
+		expectError bool
+	}{
+		{
+			description: "result of the wrapped allocator already satisfies constraints",
+			constraints: []TopologyConstraint{SameNUMANodeConstraint},
+			available:   NewDeviceSet(cores[0][0], cores[2][0], cores[4][0]),
+			required:    NewDeviceSet(),
+			size:        2,
+			expected:    NewDeviceSet(cores[0][0], cores[2][0]),
+		},
+		{
+			description: "no device set satisfies same NUMA node",
+			constraints: []TopologyConstraint{SameNUMANodeConstraint},
+			available:   NewDeviceSet(cores[0][0], cores[4][0]),
+			required:    NewDeviceSet(),
+			size:        2,
+			expectError: true,
+		},
+		{
+			description: "required devices span NUMA nodes",
+			constraints: []TopologyConstraint{SameNUMANodeConstraint},
+			available:   NewDeviceSet(cores[0][0], cores[0][1], cores[4][0]),
+			required:    NewDeviceSet(cores[0][0], cores[4][0]),
+			size:        3,
+			expectError: true,
+		},
+		{
+			description: "search other cards when the wrapped allocator crosses NUMA nodes",
+			constraints: []TopologyConstraint{SameNUMANodeConstraint},
+			available:   NewDeviceSet(cores[0][0], cores[0][1], cores[0][2], cores[4][0], cores[5][0], cores[6][0]),
+			required:    NewDeviceSet(cores[4][0]),
+			size:        3,
+			expected:    NewDeviceSet(cores[4][0], cores[5][0], cores[6][0]),
+		},
+		{
+			description: "same host bridge prefers the switch shared with required device",
+			constraints: []TopologyConstraint{SameHostBridgeConstraint},
+			available:   NewDeviceSet(cores[0][0], cores[1][0], cores[2][0], cores[2][1], cores[3][0]),
+			required:    NewDeviceSet(cores[3][0]),
+			size:        3,
+			expected:    NewDeviceSet(cores[2][0], cores[2][1], cores[3][0]),
+		},
+		{
+			description: "no device set satisfies same host bridge",
+			constraints: []TopologyConstraint{SameHostBridgeConstraint},
+			available:   NewDeviceSet(cores[0][0], cores[2][0], cores[4][0], cores[6][0]),
+			required:    NewDeviceSet(),
+			size:        2,
+			expectError: true,
+		},
+		{
+			description: "p2p accessible devices",
+			constraints: []TopologyConstraint{P2PAccessibleConstraint, SameNUMANodeConstraint},
+			available:   NewDeviceSet(cores[0][0], cores[4][0], cores[5][0]),
+			required:    NewDeviceSet(),
+			size:        2,
+			expected:    NewDeviceSet(cores[4][0], cores[5][0]),
+		},
+		{
+			description: "not enough devices",
+			constraints: []TopologyConstraint{P2PAccessibleConstraint},
+			available:   NewDeviceSet(cores[0][0]),
+			required:    NewDeviceSet(),
+			size:        2,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			sut, err := NewConstrainedNpuAllocator(smiDevices, binPackingAllocator, tc.constraints...)
+			assert.NoError(t, err)
+
+			actual, err := sut.TryAllocate(tc.available, tc.required, tc.size)
+			if tc.expectError {
+				var unsatisfiableErr *UnsatisfiableConstraintError
+				assert.True(t, errors.As(err, &unsatisfiableErr))
+				assert.Equal(t, tc.constraints, unsatisfiableErr.Constraints)
+				assert.Equal(t, 0, sut.Allocate(tc.available, tc.required, tc.size).Len())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Truef(t, tc.expected.Equal(actual.Devices()...), "expected %v but got %v", tc.expected.Devices(), actual.Devices())
+		})
+	}
+
+	t.Run("unknown constraint", func(t *testing.T) {
+		_, err := NewConstrainedNpuAllocator(smiDevices, binPackingAllocator, TopologyConstraint("same-rack"))
+		assert.Error(t, err)
+	})
+	t.Run("topology scoring policy ranks combinations", func(t *testing.T) {
+		// the spread allocator crosses NUMA nodes, so combinations of cards under NUMA node 1 are ranked by scores.
+		spreadAllocator, err := NewSpreadNpuAllocator(smiDevices)
+		assert.NoError(t, err)
+
+		available := NewDeviceSet(cores[0][0], cores[4][0], cores[5][0], cores[6][0])
+		required := NewDeviceSet(cores[4][0])
+
+		tests := []struct {
+			description string
+			opts        []AllocatorOption
+			expected    DeviceSet
+		}{
+			{
+				description: "default policy prefers the same host bridge",
+				expected:    NewDeviceSet(cores[4][0], cores[5][0]),
+			},
+			{
+				description: "custom policy prefers cpu links",
+				opts: []AllocatorOption{WithTopologyScoringPolicy(TopologyScoringPolicy{
+					LinkTypeWeights: LinkTypeWeights{Noc: 70, HostBridge: 20, Cpu: 30, Interconnect: 10},
+				})},
+				expected: NewDeviceSet(cores[4][0], cores[6][0]),
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.description, func(t *testing.T) {
+				sut, err := NewConstrainedNpuAllocatorWithOptions(smiDevices, spreadAllocator, []TopologyConstraint{SameNUMANodeConstraint}, tc.opts...)
+				assert.NoError(t, err)
+
+				actual, err := sut.TryAllocate(available, required, 2)
+				assert.NoError(t, err)
+				assert.Truef(t, tc.expected.Equal(actual.Devices()...), "expected %v but got %v", tc.expected.Devices(), actual.Devices())
+			})
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package npu_allocator
+
+import (
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+	"gonum.org/v1/gonum/stat/combin"
+)
+
+// maxAdjacencyCandidates is the number of candidate partitions in a card up to which every combination is evaluated.
+const maxAdjacencyCandidates = 16
+
+// coreAdjacencyScore scores how well partitions of the same card form a single core range.
+// For each card with two or more partitions, a contiguous range scores the number of its cores,
+// and the score doubles if the range is also aligned like combined device files such as "pe0-1" and "pe4-7".
+// Devices which are not partitions, such as exclusive devices, score 0.
+func coreAdjacencyScore(devices []Device) uint {
+	partitionsByHintKey := make(map[TopologyHintKey][]furiosa_device.Partition)
+	for _, device := range devices {
+		_, partition, err := furiosa_device.ParseDeviceID(device.ID())
+		if err != nil || partition == nil {
+			return 0
+		}
+
+		partitionsByHintKey[device.TopologyHintKey()] = append(partitionsByHintKey[device.TopologyHintKey()], *partition)
+	}
+
+	total := uint(0)
+	for _, partitions := range partitionsByHintKey {
+		if len(partitions) < 2 {
+			continue
+		}
+
+		start, end, covered := partitions[0].Start, partitions[0].End, 0
+		for _, partition := range partitions {
+			start, end = min(start, partition.Start), max(end, partition.End)
+			covered += partition.End - partition.Start + 1
+		}
+
+		span := end - start + 1
+		if covered != span {
+			continue
+		}
+
+		total += uint(span)
+		if span&(span-1) == 0 && start%span == 0 {
+			total += uint(span)
+		}
+	}
+
+	return total
+}
+
+// selectAdjacentDevices picks `count` devices from candidates of a card, maximizing coreAdjacencyScore together with
+// devices already collected from the card. Ties are broken by the order of DeviceSet.
+func selectAdjacentDevices(candidates DeviceSet, collected []Device, count int) []Device {
+	devices := candidates.Devices()
+	if count >= len(devices) {
+		return devices
+	}
+
+	if count <= 0 {
+		return nil
+	}
+
+	if len(devices) > maxAdjacencyCandidates {
+		return devices[:count]
+	}
+
+	var best []Device
+	var highestScore uint
+	for _, indices := range combin.Combinations(len(devices), count) {
+		selected := make([]Device, 0, count)
+		for _, idx := range indices {
+			selected = append(selected, devices[idx])
+		}
+
+		score := coreAdjacencyScore(append(append(make([]Device, 0, len(collected)+count), collected...), selected...))
+		if best == nil || score > highestScore {
+			best = selected
+			highestScore = score
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,109 @@
+package npu_allocator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// buildMockPartition builds a partition of the card with the given hint key, such as "0_cores_2-3".
+func buildMockPartition(index int, hintKey TopologyHintKey, partition string) Device {
+	return NewMockDevice(index, string(hintKey)+"_cores_"+partition, hintKey)
+}
+
+func TestCoreAdjacencyScore(t *testing.T) {
+	tests := []struct {
+		description string
+		devices     []Device
+		expected    uint
+	}{
+		{
+			description: "single partition has no adjacency",
+			devices:     []Device{buildMockPartition(0, "0", "0")},
+			expected:    0,
+		},
+		{
+			description: "aligned pair of single cores",
+			devices:     []Device{buildMockPartition(0, "0", "0"), buildMockPartition(0, "0", "1")},
+			expected:    4,
+		},
+		{
+			description: "contiguous but unaligned pair",
+			devices:     []Device{buildMockPartition(0, "0", "1"), buildMockPartition(0, "0", "2")},
+			expected:    2,
+		},
+		{
+			description: "distant cores",
+			devices:     []Device{buildMockPartition(0, "0", "0"), buildMockPartition(0, "0", "5")},
+			expected:    0,
+		},
+		{
+			description: "aligned quad of dual cores",
+			devices:     []Device{buildMockPartition(0, "0", "4-5"), buildMockPartition(0, "0", "6-7")},
+			expected:    8,
+		},
+		{
+			description: "partitions of different cards are scored per card",
+			devices: []Device{
+				buildMockPartition(0, "0", "0"), buildMockPartition(0, "0", "1"),
+				buildMockPartition(1, "1", "2"), buildMockPartition(1, "1", "5"),
+			},
+			expected: 4,
+		},
+		{
+			description: "exclusive devices",
+			devices:     []Device{buildMockDevice(0), buildMockDevice(1)},
+			expected:    0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, coreAdjacencyScore(tc.devices))
+		})
+	}
+}
+
+func TestAllocatorsPreferAdjacentCores(t *testing.T) {
+	available := NewDeviceSet(
+		buildMockPartition(0, "0", "0"),
+		buildMockPartition(0, "0", "2"),
+		buildMockPartition(0, "0", "3"),
+		buildMockPartition(0, "0", "5"),
+	)
+
+	binPacking, err := NewMockBinPackingNpuAllocator(buildStaticHintMatrixForTwoSocketBalancedConfig())
+	assert.NoError(t, err)
+
+	scoreBased, err := NewMockScoreBasedOptimalNpuAllocator(mockTopologyHintProvider(buildStaticHintMatrixForTwoSocketBalancedConfig()))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		description string
+		required    DeviceSet
+		size        int
+		expected    DeviceSet
+	}{
+		{
+			description: "aligned pair is preferred over lower cores",
+			required:    NewDeviceSet(),
+			size:        2,
+			expected:    NewDeviceSet(buildMockPartition(0, "0", "2"), buildMockPartition(0, "0", "3")),
+		},
+		{
+			description: "adjacent core of the required partition is preferred",
+			required:    NewDeviceSet(buildMockPartition(0, "0", "3")),
+			size:        2,
+			expected:    NewDeviceSet(buildMockPartition(0, "0", "2"), buildMockPartition(0, "0", "3")),
+		},
+	}
+
+	for _, tc := range tests {
+		for name, allocator := range map[string]NpuAllocator{"bin packing": binPacking, "score based": scoreBased} {
+			t.Run(name+" "+tc.description, func(t *testing.T) {
+				actual := allocator.Allocate(available, tc.required, tc.size)
+				assert.Truef(t, tc.expected.Equal(actual.Devices()...), "expected %v but got %v", tc.expected.Devices(), actual.Devices())
+			})
+		}
+	}
+}
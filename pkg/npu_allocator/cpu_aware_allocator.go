@@ -0,0 +1,136 @@
+package npu_allocator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/sysfs"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/util"
+)
+
+var _ CPUAwareNpuAllocator = (*cpuAwareNpuAllocator)(nil)
+
+// CPUAwareNpuAllocator recommends CPUs local to the allocated devices alongside DeviceSet.
+type CPUAwareNpuAllocator interface {
+	NpuAllocator
+
+	// AllocateWithCPUs returns allocated DeviceSet and recommended cpuset string such as "0-3,8".
+	// If cpuCount is zero or less, every CPU local to the devices except reserved CPUs is recommended.
+	AllocateWithCPUs(available DeviceSet, required DeviceSet, size int, cpuCount int) (DeviceSet, string, error)
+}
+
+type cpuAwareNpuAllocator struct {
+	NpuAllocator
+
+	localCPUsByHintKey map[TopologyHintKey][]int
+	reservedCPUs       map[int]struct{}
+}
+
+// NewCPUAwareNpuAllocator wraps the allocator with CPU recommendation based on sysfs mounted under the root.
+// CPUs local to each device are read from `local_cpulist` of the PCI device, or from cpulist of its NUMA node if not reported.
+// reservedCPUs is a cpuset string of CPUs that must never be recommended, such as CPUs reserved for system daemons.
+func NewCPUAwareNpuAllocator(allocator NpuAllocator, root string, bdfs []string, reservedCPUs string) (CPUAwareNpuAllocator, error) {
+	localCPUsByHintKey := make(map[TopologyHintKey][]int, len(bdfs))
+	for _, bdf := range bdfs {
+		pciDevice, err := sysfs.ReadPCIDevice(root, bdf)
+		if err != nil {
+			return nil, err
+		}
+
+		pciBusID, err := util.ParseBusIDFromBDF(bdf)
+		if err != nil {
+			return nil, err
+		}
+
+		cpuList := pciDevice.LocalCPUList
+		if cpuList == "" {
+			if pciDevice.NUMANode < 0 {
+				return nil, fmt.Errorf("couldn't find local cpus of pci device %s", bdf)
+			}
+
+			cpuList, err = sysfs.ReadNUMANodeCPUList(root, pciDevice.NUMANode)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		localCPUs, err := util.ParseCPUSet(cpuList)
+		if err != nil {
+			return nil, err
+		}
+
+		localCPUsByHintKey[TopologyHintKey(pciBusID)] = localCPUs
+	}
+
+	return newCPUAwareNpuAllocator(allocator, localCPUsByHintKey, reservedCPUs)
+}
+
+func NewMockCPUAwareNpuAllocator(allocator NpuAllocator, localCPUsByHintKey map[TopologyHintKey][]int, reservedCPUs string) (CPUAwareNpuAllocator, error) {
+	return newCPUAwareNpuAllocator(allocator, localCPUsByHintKey, reservedCPUs)
+}
+
+func newCPUAwareNpuAllocator(allocator NpuAllocator, localCPUsByHintKey map[TopologyHintKey][]int, reservedCPUs string) (CPUAwareNpuAllocator, error) {
+	reserved, err := util.ParseCPUSet(reservedCPUs)
+	if err != nil {
+		return nil, err
+	}
+
+	reservedSet := make(map[int]struct{}, len(reserved))
+	for _, cpu := range reserved {
+		reservedSet[cpu] = struct{}{}
+	}
+
+	return &cpuAwareNpuAllocator{
+		NpuAllocator:       allocator,
+		localCPUsByHintKey: localCPUsByHintKey,
+		reservedCPUs:       reservedSet,
+	}, nil
+}
+
+func (c *cpuAwareNpuAllocator) AllocateWithCPUs(available DeviceSet, required DeviceSet, size int, cpuCount int) (DeviceSet, string, error) {
+	allocated := c.Allocate(available, required, size)
+	if allocated.Len() != size {
+		return nil, "", fmt.Errorf("couldn't allocate %d devices", size)
+	}
+
+	cpuSet, err := c.recommendCPUs(allocated, cpuCount)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return allocated, cpuSet, nil
+}
+
+// recommendCPUs takes CPUs local to the devices in ascending order, skipping reserved CPUs.
+func (c *cpuAwareNpuAllocator) recommendCPUs(devices DeviceSet, cpuCount int) (string, error) {
+	localCPUSet := make(map[int]struct{})
+	for _, key := range uniqueHintKeys(devices.Devices()) {
+		localCPUs, ok := c.localCPUsByHintKey[key]
+		if !ok {
+			return "", fmt.Errorf("couldn't find local cpus of topology hint key %q", key)
+		}
+
+		for _, cpu := range localCPUs {
+			if _, reserved := c.reservedCPUs[cpu]; !reserved {
+				localCPUSet[cpu] = struct{}{}
+			}
+		}
+	}
+
+	candidates := make([]int, 0, len(localCPUSet))
+	for cpu := range localCPUSet {
+		candidates = append(candidates, cpu)
+	}
+
+	if cpuCount <= 0 {
+		return util.FormatCPUSet(candidates), nil
+	}
+
+	if len(candidates) < cpuCount {
+		return "", fmt.Errorf("only %d local cpus are available, but %d cpus are requested", len(candidates), cpuCount)
+	}
+
+	sort.Ints(candidates)
+
+	return util.FormatCPUSet(candidates[:cpuCount]), nil
+}
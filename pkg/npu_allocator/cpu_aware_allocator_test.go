@@ -0,0 +1,115 @@
+package npu_allocator
+
+import (
+	"testing"
+
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/sysfs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCPUAwareNpuAllocator(t *testing.T) {
+	localCPUsByHintKey := map[TopologyHintKey][]int{
+		"0": {0, 1, 2, 3, 4, 5, 6, 7},
+		"1": {0, 1, 2, 3, 4, 5, 6, 7},
+		"2": {0, 1, 2, 3, 4, 5, 6, 7},
+		"3": {0, 1, 2, 3, 4, 5, 6, 7},
+		"4": {8, 9, 10, 11, 12, 13, 14, 15},
+		"5": {8, 9, 10, 11, 12, 13, 14, 15},
+		"6": {8, 9, 10, 11, 12, 13, 14, 15},
+		"7": {8, 9, 10, 11, 12, 13, 14, 15},
+	}
+
+	tests := []struct {
+		description     string
+		required        DeviceSet
+		size            int
+		cpuCount        int
+		reservedCPUs    string
+		expectedDevices DeviceSet
+		expectedCPUSet  string
+		expectError     bool
+	}{
+		{
+			description:     "every local cpu is recommended",
+			required:        NewDeviceSet(),
+			size:            2,
+			cpuCount:        0,
+			reservedCPUs:    "",
+			expectedDevices: NewDeviceSet(buildMockDevice(0), buildMockDevice(1)),
+			expectedCPUSet:  "0-7",
+		},
+		{
+			description:     "reserved cpus are excluded",
+			required:        NewDeviceSet(),
+			size:            2,
+			cpuCount:        4,
+			reservedCPUs:    "0-1",
+			expectedDevices: NewDeviceSet(buildMockDevice(0), buildMockDevice(1)),
+			expectedCPUSet:  "2-5",
+		},
+		{
+			description:     "devices across numa nodes",
+			required:        NewDeviceSet(buildMockDevice(0), buildMockDevice(4)),
+			size:            2,
+			cpuCount:        0,
+			reservedCPUs:    "0,8",
+			expectedDevices: NewDeviceSet(buildMockDevice(0), buildMockDevice(4)),
+			expectedCPUSet:  "1-7,9-15",
+		},
+		{
+			description:  "not enough local cpus",
+			required:     NewDeviceSet(),
+			size:         1,
+			cpuCount:     8,
+			reservedCPUs: "7",
+			expectError:  true,
+		},
+		{
+			description:  "not enough devices",
+			required:     NewDeviceSet(),
+			size:         9,
+			cpuCount:     1,
+			reservedCPUs: "",
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			allocator, _ := NewMockBinPackingNpuAllocator(buildStaticHintMatrixForTwoSocketBalancedConfig())
+			sut, err := NewMockCPUAwareNpuAllocator(allocator, localCPUsByHintKey, tc.reservedCPUs)
+			assert.NoError(t, err)
+
+			devices, cpuSet, err := sut.AllocateWithCPUs(buildMockDeviceSet(0, 7), tc.required, tc.size, tc.cpuCount)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Truef(t, tc.expectedDevices.Equal(devices.Devices()...), "expected %v but got %v", tc.expectedDevices.Devices(), devices.Devices())
+			assert.Equal(t, tc.expectedCPUSet, cpuSet)
+		})
+	}
+}
+
+func TestNewCPUAwareNpuAllocator(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, sysfs.CreateMockPCIDevice(root, []string{"pci0000:16", "0000:16:01.0", "0000:27:00.0"}, 0, "0-3"))
+	assert.NoError(t, sysfs.CreateMockPCIDevice(root, []string{"pci0000:8a", "0000:8a:01.0", "0000:9e:00.0"}, 1, ""))
+	assert.NoError(t, sysfs.CreateMockNUMANode(root, 1, "16-19"))
+
+	allocator, _ := NewMockBinPackingNpuAllocator(TopologyHintMatrix{})
+	sut, err := NewCPUAwareNpuAllocator(allocator, root, []string{"0000:27:00.0", "0000:9e:00.0"}, "16")
+	assert.NoError(t, err)
+
+	available := NewDeviceSet(NewMockDevice(0, "0", "27"), NewMockDevice(1, "1", "9e"))
+
+	_, cpuSet, err := sut.AllocateWithCPUs(available, NewDeviceSet(NewMockDevice(1, "1", "9e")), 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "17-19", cpuSet)
+
+	_, cpuSet, err = sut.AllocateWithCPUs(available, available, 2, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "0-3,17", cpuSet)
+}
@@ -0,0 +1,117 @@
+package npu_allocator
+
+import (
+	"fmt"
+	"sort"
+)
+
+// GangRequest is a single device request of a gang, such as a container of a pod.
+type GangRequest struct {
+	Required DeviceSet
+	Size     int
+}
+
+// GangNpuAllocator allocates devices for several requests at once.
+type GangNpuAllocator interface {
+	// AllocateGang returns disjoint DeviceSets in the same order of the given requests.
+	AllocateGang(available DeviceSet, requests []GangRequest) ([]DeviceSet, error)
+}
+
+var _ GangNpuAllocator = (*gangNpuAllocator)(nil)
+
+type gangNpuAllocator struct {
+	allocator NpuAllocator
+}
+
+// NewGangNpuAllocator returns GangNpuAllocator built on top of the given NpuAllocator.
+// The whole gang is allocated first as a single request to keep related requests close,
+// and then the allocated pool is split into each request to maximize locality within each request.
+func NewGangNpuAllocator(allocator NpuAllocator) GangNpuAllocator {
+	return &gangNpuAllocator{allocator: allocator}
+}
+
+func (g *gangNpuAllocator) AllocateGang(available DeviceSet, requests []GangRequest) ([]DeviceSet, error) {
+	// Step 1: validate requests and collect required devices of the whole gang.
+	allRequired := NewDeviceSet()
+	totalSize := 0
+	for idx, request := range requests {
+		required := request.Required
+		if required == nil {
+			required = NewDeviceSet()
+		}
+
+		if request.Size < required.Len() {
+			return nil, fmt.Errorf("request %d: size %d is less than the number of required devices %d", idx, request.Size, required.Len())
+		}
+
+		if required.Len() > 0 && !available.Contains(required.Devices()...) {
+			return nil, fmt.Errorf("request %d: required devices are not available", idx)
+		}
+
+		for _, device := range required.Devices() {
+			if allRequired.Contains(device) {
+				return nil, fmt.Errorf("request %d: required device %s is shared with another request", idx, device.ID())
+			}
+		}
+
+		allRequired.Insert(required.Devices()...)
+		totalSize += request.Size
+	}
+
+	if totalSize > available.Len() {
+		return nil, fmt.Errorf("requested %d devices but only %d devices are available", totalSize, available.Len())
+	}
+
+	results := make([]DeviceSet, len(requests))
+	if totalSize == 0 {
+		for idx := range results {
+			results[idx] = NewDeviceSet()
+		}
+
+		return results, nil
+	}
+
+	// Step 2: allocate the pool for the whole gang.
+	pool := g.allocator.Allocate(available, allRequired, totalSize)
+	if pool.Len() != totalSize {
+		return nil, fmt.Errorf("couldn't allocate %d devices for the gang, got %d", totalSize, pool.Len())
+	}
+
+	// Step 3: split the pool, larger requests first because they are harder to place with good locality.
+	order := make([]int, len(requests))
+	for idx := range order {
+		order[idx] = idx
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return requests[order[i]].Size > requests[order[j]].Size
+	})
+
+	unprocessedRequired := NewDeviceSet(allRequired.Devices()...)
+	for _, idx := range order {
+		request := requests[idx]
+		required := request.Required
+		if required == nil {
+			required = NewDeviceSet()
+		}
+
+		unprocessedRequired = unprocessedRequired.Difference(required.Devices()...)
+
+		// required devices of other requests must not be taken.
+		candidates := pool.Difference(unprocessedRequired.Devices()...)
+
+		result := NewDeviceSet()
+		if request.Size > 0 {
+			result = g.allocator.Allocate(candidates, required, request.Size)
+		}
+
+		if result.Len() != request.Size {
+			return nil, fmt.Errorf("request %d: couldn't allocate %d devices from the gang pool, got %d", idx, request.Size, result.Len())
+		}
+
+		pool = pool.Difference(result.Devices()...)
+		results[idx] = result
+	}
+
+	return results, nil
+}
@@ -0,0 +1,133 @@
+package npu_allocator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGangNpuAllocator(t *testing.T) {
+	binPackingAllocator, _ := NewMockBinPackingNpuAllocator(buildStaticHintMatrixForTwoSocketBalancedConfig())
+	scoreBasedAllocator, _ := NewMockScoreBasedOptimalNpuAllocator(mockTopologyHintProvider(buildStaticHintMatrixForTwoSocketBalancedConfig()))
+
+	tests := []struct {
+		description string
+		available   DeviceSet
+		requests    []GangRequest
+		expected    []DeviceSet
+		expectError bool
+	}{
+		{
+			description: "two requests are placed under the same socket",
+			available:   buildMockDeviceSet(0, 7),
+			requests: []GangRequest{
+				{Required: NewDeviceSet(), Size: 2},
+				{Required: NewDeviceSet(), Size: 2},
+			},
+			expected: []DeviceSet{
+				NewDeviceSet(buildMockDevice(0), buildMockDevice(1)),
+				NewDeviceSet(buildMockDevice(2), buildMockDevice(3)),
+			},
+		},
+		{
+			description: "larger request is placed first",
+			available:   buildMockDeviceSet(0, 7),
+			requests: []GangRequest{
+				{Required: NewDeviceSet(), Size: 1},
+				{Required: NewDeviceSet(), Size: 2},
+			},
+			expected: []DeviceSet{
+				NewDeviceSet(buildMockDevice(2)),
+				NewDeviceSet(buildMockDevice(0), buildMockDevice(1)),
+			},
+		},
+		{
+			description: "required device pulls the gang to another socket",
+			available:   buildMockDeviceSet(0, 7),
+			requests: []GangRequest{
+				{Required: NewDeviceSet(buildMockDevice(4)), Size: 2},
+				{Required: NewDeviceSet(), Size: 2},
+			},
+			expected: []DeviceSet{
+				NewDeviceSet(buildMockDevice(4), buildMockDevice(5)),
+				NewDeviceSet(buildMockDevice(6), buildMockDevice(7)),
+			},
+		},
+		{
+			description: "required device of another request is not taken",
+			available:   buildMockDeviceSet(0, 7),
+			requests: []GangRequest{
+				{Required: NewDeviceSet(), Size: 2},
+				{Required: NewDeviceSet(buildMockDevice(1)), Size: 1},
+			},
+			expected: []DeviceSet{
+				NewDeviceSet(buildMockDevice(0), buildMockDevice(2)),
+				NewDeviceSet(buildMockDevice(1)),
+			},
+		},
+		{
+			description: "empty requests",
+			available:   buildMockDeviceSet(0, 7),
+			requests: []GangRequest{
+				{Required: nil, Size: 0},
+			},
+			expected: []DeviceSet{
+				NewDeviceSet(),
+			},
+		},
+		{
+			description: "not enough devices",
+			available:   buildMockDeviceSet(0, 3),
+			requests: []GangRequest{
+				{Required: NewDeviceSet(), Size: 3},
+				{Required: NewDeviceSet(), Size: 2},
+			},
+			expectError: true,
+		},
+		{
+			description: "required devices are shared",
+			available:   buildMockDeviceSet(0, 7),
+			requests: []GangRequest{
+				{Required: NewDeviceSet(buildMockDevice(0)), Size: 2},
+				{Required: NewDeviceSet(buildMockDevice(0)), Size: 2},
+			},
+			expectError: true,
+		},
+		{
+			description: "required devices exceed size",
+			available:   buildMockDeviceSet(0, 7),
+			requests: []GangRequest{
+				{Required: NewDeviceSet(buildMockDevice(0), buildMockDevice(1)), Size: 1},
+			},
+			expectError: true,
+		},
+		{
+			description: "required devices are not available",
+			available:   buildMockDeviceSet(0, 3),
+			requests: []GangRequest{
+				{Required: NewDeviceSet(buildMockDevice(5)), Size: 1},
+			},
+			expectError: true,
+		},
+	}
+
+	for name, allocator := range map[string]NpuAllocator{"bin packing": binPackingAllocator, "score based": scoreBasedAllocator} {
+		for _, tc := range tests {
+			t.Run(name+": "+tc.description, func(t *testing.T) {
+				sut := NewGangNpuAllocator(allocator)
+
+				actual, err := sut.AllocateGang(tc.available, tc.requests)
+				if tc.expectError {
+					assert.Error(t, err)
+					return
+				}
+
+				assert.NoError(t, err)
+				assert.Len(t, actual, len(tc.expected))
+				for idx := range tc.expected {
+					assert.Truef(t, tc.expected[idx].Equal(actual[idx].Devices()...), "expected %v but got %v", tc.expected[idx].Devices(), actual[idx].Devices())
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package npu_allocator
+
+import (
+	"fmt"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+)
+
+var _ LiveStateFilter = (*liveStateFilter)(nil)
+var _ LiveStateAwareNpuAllocator = (*liveStateAwareNpuAllocator)(nil)
+
+// FilterReason describes why a device was filtered out.
+type FilterReason string
+
+const (
+	FilterReasonUnknownDevice    FilterReason = "unknown-device"
+	FilterReasonStateUnavailable FilterReason = "state-unavailable"
+	FilterReasonNotAlive         FilterReason = "not-alive"
+	FilterReasonCoreOccupied     FilterReason = "core-occupied"
+)
+
+// FilteredDevice is a device filtered out by LiveStateFilter with the reason.
+type FilteredDevice struct {
+	Device Device
+	Reason FilterReason
+	Detail string
+}
+
+// LiveStateFilter drops devices based on the live state reported by smi.Device.
+type LiveStateFilter interface {
+	// Filter returns devices passed the filter, and devices filtered out with reasons.
+	Filter(devices DeviceSet) (DeviceSet, []FilteredDevice)
+}
+
+type liveStateFilter struct {
+	smiDevicesByUUID map[string]smi.Device
+}
+
+// NewLiveStateFilter returns LiveStateFilter that drops devices whose card is not alive, and devices whose cores are occupied.
+// Occupied cores are usually left behind by crashed processes.
+func NewLiveStateFilter(smiDevices []smi.Device) (LiveStateFilter, error) {
+	smiDevicesByUUID := make(map[string]smi.Device, len(smiDevices))
+	for _, smiDevice := range smiDevices {
+		deviceInfo, err := smiDevice.DeviceInfo()
+		if err != nil {
+			return nil, err
+		}
+
+		smiDevicesByUUID[deviceInfo.UUID()] = smiDevice
+	}
+
+	return &liveStateFilter{smiDevicesByUUID: smiDevicesByUUID}, nil
+}
+
+// cardState is live state of a card, read once per Filter call.
+type cardState struct {
+	reason        FilterReason
+	detail        string
+	occupiedCores map[int]struct{}
+}
+
+func readCardState(smiDevice smi.Device) *cardState {
+	alive, err := smiDevice.Liveness()
+	if err != nil {
+		return &cardState{reason: FilterReasonStateUnavailable, detail: fmt.Sprintf("couldn't read liveness: %s", err)}
+	}
+
+	if !alive {
+		return &cardState{reason: FilterReasonNotAlive, detail: "liveness is false"}
+	}
+
+	coreStatuses, err := smiDevice.CoreStatus()
+	if err != nil {
+		return &cardState{reason: FilterReasonStateUnavailable, detail: fmt.Sprintf("couldn't read core status: %s", err)}
+	}
+
+	occupiedCores := make(map[int]struct{})
+	for _, peStatus := range coreStatuses.PeStatus() {
+		if peStatus.Status() == smi.CoreStatusOccupied {
+			occupiedCores[int(peStatus.Core())] = struct{}{}
+		}
+	}
+
+	return &cardState{occupiedCores: occupiedCores}
+}
+
+func (f *liveStateFilter) Filter(devices DeviceSet) (DeviceSet, []FilteredDevice) {
+	passed := NewDeviceSet()
+	var filtered []FilteredDevice
+
+	cardStates := make(map[string]*cardState)
+	for _, device := range devices.Devices() {
+		uuid, partition, err := furiosa_device.ParseDeviceID(device.ID())
+		if err != nil {
+			filtered = append(filtered, FilteredDevice{Device: device, Reason: FilterReasonUnknownDevice, Detail: err.Error()})
+			continue
+		}
+
+		smiDevice, ok := f.smiDevicesByUUID[uuid]
+		if !ok {
+			filtered = append(filtered, FilteredDevice{Device: device, Reason: FilterReasonUnknownDevice, Detail: fmt.Sprintf("card %s is not found", uuid)})
+			continue
+		}
+
+		state, ok := cardStates[uuid]
+		if !ok {
+			state = readCardState(smiDevice)
+			cardStates[uuid] = state
+		}
+
+		if state.reason != "" {
+			filtered = append(filtered, FilteredDevice{Device: device, Reason: state.reason, Detail: state.detail})
+			continue
+		}
+
+		if core, occupied := findOccupiedCore(state.occupiedCores, partition); occupied {
+			filtered = append(filtered, FilteredDevice{Device: device, Reason: FilterReasonCoreOccupied, Detail: fmt.Sprintf("core %d is occupied", core)})
+			continue
+		}
+
+		passed.Insert(device)
+	}
+
+	return passed, filtered
+}
+
+// findOccupiedCore returns the lowest occupied core in the partition. Every core belongs to an exclusive device with nil partition.
+func findOccupiedCore(occupiedCores map[int]struct{}, partition *furiosa_device.Partition) (int, bool) {
+	found, lowest := false, 0
+	for core := range occupiedCores {
+		if partition != nil && (core < partition.Start || core > partition.End) {
+			continue
+		}
+
+		if !found || core < lowest {
+			found, lowest = true, core
+		}
+	}
+
+	return lowest, found
+}
+
+// LiveStateAwareNpuAllocator filters available devices with LiveStateFilter before allocation.
+type LiveStateAwareNpuAllocator interface {
+	NpuAllocator
+
+	// AllocateWithReport returns allocated DeviceSet and devices filtered out by LiveStateFilter.
+	AllocateWithReport(available DeviceSet, required DeviceSet, size int) (DeviceSet, []FilteredDevice)
+}
+
+type liveStateAwareNpuAllocator struct {
+	allocator NpuAllocator
+	filter    LiveStateFilter
+}
+
+// NewLiveStateAwareNpuAllocator wraps the allocator with LiveStateFilter.
+// Required devices are never filtered out since they are already determined by the caller.
+func NewLiveStateAwareNpuAllocator(allocator NpuAllocator, filter LiveStateFilter) LiveStateAwareNpuAllocator {
+	return &liveStateAwareNpuAllocator{
+		allocator: allocator,
+		filter:    filter,
+	}
+}
+
+func (l *liveStateAwareNpuAllocator) Allocate(available DeviceSet, required DeviceSet, size int) DeviceSet {
+	allocated, _ := l.AllocateWithReport(available, required, size)
+
+	return allocated
+}
+
+func (l *liveStateAwareNpuAllocator) AllocateWithReport(available DeviceSet, required DeviceSet, size int) (DeviceSet, []FilteredDevice) {
+	passed, filtered := l.filter.Filter(available.Difference(required.Devices()...))
+
+	return l.allocator.Allocate(passed.Union(required.Devices()...), required, size), filtered
+}
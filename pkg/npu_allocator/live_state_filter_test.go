@@ -0,0 +1,176 @@
+package npu_allocator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPeStatus struct {
+	core   uint32
+	status smi.CoreStatus
+}
+
+func (m *mockPeStatus) Core() uint32 {
+	return m.core
+}
+
+func (m *mockPeStatus) Status() smi.CoreStatus {
+	return m.status
+}
+
+type mockCoreStatuses struct {
+	peStatus []smi.PeStatus
+}
+
+func (m *mockCoreStatuses) PeStatus() []smi.PeStatus {
+	return m.peStatus
+}
+
+// liveStateOverriddenDevice overrides liveness and core status of the embedded smi.Device.
+type liveStateOverriddenDevice struct {
+	smi.Device
+	alive         bool
+	livenessErr   error
+	occupiedCores []uint32
+}
+
+func (d *liveStateOverriddenDevice) Liveness() (bool, error) {
+	return d.alive, d.livenessErr
+}
+
+func (d *liveStateOverriddenDevice) CoreStatus() (smi.CoreStatuses, error) {
+	statuses := &mockCoreStatuses{}
+	for core := uint32(0); core < 8; core++ {
+		status := smi.CoreStatusAvailable
+		for _, occupied := range d.occupiedCores {
+			if occupied == core {
+				status = smi.CoreStatusOccupied
+			}
+		}
+
+		statuses.peStatus = append(statuses.peStatus, &mockPeStatus{core: core, status: status})
+	}
+
+	return statuses, nil
+}
+
+func TestLiveStateFilter(t *testing.T) {
+	origins := smi.GetStaticMockDevices(smi.ArchRngd)[:4]
+	smiDevices := []smi.Device{
+		&liveStateOverriddenDevice{Device: origins[0], alive: true},
+		&liveStateOverriddenDevice{Device: origins[1], alive: false},
+		&liveStateOverriddenDevice{Device: origins[2], alive: true, occupiedCores: []uint32{5}},
+		&liveStateOverriddenDevice{Device: origins[3], livenessErr: errors.New("timeout")},
+	}
+
+	furiosaDevices, err := furiosa_device.NewFuriosaDevices(smiDevices, nil, furiosa_device.QuadCorePolicy)
+	assert.NoError(t, err)
+
+	devices := NewDeviceSet()
+	for _, furiosaDevice := range furiosaDevices {
+		devices.Insert(NewDevice(furiosaDevice))
+	}
+
+	unknownDevice := NewMockDevice(100, "B76AAD68-6855-40B1-9E86-D080852D1C80", "ff")
+	devices.Insert(unknownDevice)
+
+	sut, err := NewLiveStateFilter(smiDevices)
+	assert.NoError(t, err)
+
+	passed, filtered := sut.Filter(devices)
+
+	passedIDs := make([]string, 0, passed.Len())
+	for _, device := range passed.Devices() {
+		passedIDs = append(passedIDs, device.ID())
+	}
+
+	assert.Equal(t, []string{
+		"A76AAD68-6855-40B1-9E86-D080852D1C80_cores_0-3",
+		"A76AAD68-6855-40B1-9E86-D080852D1C80_cores_4-7",
+		"A76AAD68-6855-40B1-9E86-D080852D1C82_cores_0-3",
+	}, passedIDs)
+
+	reasons := make(map[string]FilterReason)
+	for _, filteredDevice := range filtered {
+		reasons[filteredDevice.Device.ID()] = filteredDevice.Reason
+	}
+
+	assert.Equal(t, map[string]FilterReason{
+		"A76AAD68-6855-40B1-9E86-D080852D1C81_cores_0-3": FilterReasonNotAlive,
+		"A76AAD68-6855-40B1-9E86-D080852D1C81_cores_4-7": FilterReasonNotAlive,
+		"A76AAD68-6855-40B1-9E86-D080852D1C82_cores_4-7": FilterReasonCoreOccupied,
+		"A76AAD68-6855-40B1-9E86-D080852D1C83_cores_0-3": FilterReasonStateUnavailable,
+		"A76AAD68-6855-40B1-9E86-D080852D1C83_cores_4-7": FilterReasonStateUnavailable,
+		"B76AAD68-6855-40B1-9E86-D080852D1C80":           FilterReasonUnknownDevice,
+	}, reasons)
+}
+
+func TestLiveStateAwareNpuAllocator(t *testing.T) {
+	origins := smi.GetStaticMockDevices(smi.ArchRngd)
+	smiDevices := make([]smi.Device, 0, len(origins))
+	for idx, origin := range origins {
+		smiDevices = append(smiDevices, &liveStateOverriddenDevice{Device: origin, alive: idx != 1})
+	}
+
+	furiosaDevices, err := furiosa_device.NewFuriosaDevices(smiDevices, nil, furiosa_device.NonePolicy)
+	assert.NoError(t, err)
+
+	available := NewDeviceSet()
+	for _, furiosaDevice := range furiosaDevices {
+		available.Insert(NewDevice(furiosaDevice))
+	}
+
+	filter, err := NewLiveStateFilter(smiDevices)
+	assert.NoError(t, err)
+
+	// topology is built from origins, since mock devices can't resolve link types of wrapped devices.
+	allocator, err := NewBinPackingNpuAllocator(origins)
+	assert.NoError(t, err)
+
+	sut := NewLiveStateAwareNpuAllocator(allocator, filter)
+
+	t.Run("dead card is not allocated", func(t *testing.T) {
+		actual, filtered := sut.AllocateWithReport(available, NewDeviceSet(), 2)
+
+		hintKeys := make([]TopologyHintKey, 0, actual.Len())
+		for _, device := range actual.Devices() {
+			hintKeys = append(hintKeys, device.TopologyHintKey())
+		}
+
+		assert.Equal(t, []TopologyHintKey{"51", "57"}, hintKeys)
+		assert.Len(t, filtered, 1)
+		assert.Equal(t, FilterReasonNotAlive, filtered[0].Reason)
+	})
+
+	t.Run("required device is kept", func(t *testing.T) {
+		required := NewDeviceSet(available.Devices()[1])
+		actual, filtered := sut.AllocateWithReport(available, required, 2)
+
+		assert.True(t, actual.Contains(required.Devices()...))
+		assert.Empty(t, filtered)
+	})
+
+	t.Run("live state filter plugin", func(t *testing.T) {
+		handle, err := NewPluginHandle(origins, furiosaDevices)
+		assert.NoError(t, err)
+
+		for idx, key := range []TopologyHintKey{"27", "2a", "51", "57", "9e", "a4", "c7", "ca"} {
+			handle.SmiDevices[key] = smiDevices[idx]
+		}
+
+		pipeline, err := NewPipelineNpuAllocator(PipelineConfig{
+			Filters: []PluginConfig{{Name: LiveStateFilterName}},
+			Scores:  []PluginConfig{{Name: TopologyScoreName}},
+			Select:  ExhaustiveSelectName,
+		}, handle, NewDefaultPluginRegistry())
+		assert.NoError(t, err)
+
+		actual := pipeline.Allocate(available, NewDeviceSet(), 2)
+		assert.False(t, actual.Contains(available.Devices()[1]))
+		assert.Equal(t, 2, actual.Len())
+	})
+}
@@ -92,3 +92,40 @@ func generateSameBoardMockDeviceSet(index int, cnt int, hintKey TopologyHintKey)
 
 	return devices
 }
+
+// MockTopology is a topology with devices for testing NpuAllocator implementations without real devices.
+type MockTopology struct {
+	Name               string
+	TopologyHintMatrix TopologyHintMatrix
+	Devices            DeviceSet
+}
+
+// TopologyHintProvider returns TopologyHintProvider looking up scores from the TopologyHintMatrix of the topology.
+func (m MockTopology) TopologyHintProvider() TopologyHintProvider {
+	return generateTopologyHintProvider(m.TopologyHintMatrix)
+}
+
+// MockTopologies returns mock topologies of two-socket servers with eight cards.
+// Cards are exposed as whole devices, or split into two partitions each.
+func MockTopologies() []MockTopology {
+	partitioned := NewDeviceSet()
+	for _, hintKey := range getStaticHintKeys() {
+		index, _ := strconv.Atoi(string(hintKey))
+		for i := range iter.N(2) {
+			partitioned.Insert(NewMockDevice(index, fmt.Sprintf("%s_%d", hintKey, i), hintKey))
+		}
+	}
+
+	return []MockTopology{
+		{
+			Name:               "two-socket-balanced",
+			TopologyHintMatrix: buildStaticHintMatrixForTwoSocketBalancedConfig(),
+			Devices:            buildMockDeviceSet(0, 7),
+		},
+		{
+			Name:               "two-socket-balanced-partitioned",
+			TopologyHintMatrix: buildStaticHintMatrixForTwoSocketBalancedConfig(),
+			Devices:            partitioned,
+		},
+	}
+}
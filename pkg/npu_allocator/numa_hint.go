@@ -0,0 +1,134 @@
+package npu_allocator
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+)
+
+const maxNUMANodes = 64
+
+// NUMANodeMask is a bitmask of NUMA nodes, where the n-th bit represents NUMA node n.
+type NUMANodeMask uint64
+
+// NewNUMANodeMask returns NUMANodeMask of the given NUMA nodes.
+func NewNUMANodeMask(nodes ...int) (NUMANodeMask, error) {
+	mask := NUMANodeMask(0)
+	for _, node := range nodes {
+		if node < 0 || node >= maxNUMANodes {
+			return 0, fmt.Errorf("numa node %d is out of range [0, %d)", node, maxNUMANodes)
+		}
+
+		mask |= 1 << node
+	}
+
+	return mask, nil
+}
+
+// Nodes returns sorted NUMA nodes of the mask.
+func (m NUMANodeMask) Nodes() []int {
+	nodes := make([]int, 0, m.Count())
+	for node := 0; node < maxNUMANodes; node++ {
+		if m.Has(node) {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes
+}
+
+// Has checks whether the mask contains the NUMA node.
+func (m NUMANodeMask) Has(node int) bool {
+	return node >= 0 && node < maxNUMANodes && m&(1<<node) != 0
+}
+
+// Count returns the number of NUMA nodes of the mask.
+func (m NUMANodeMask) Count() int {
+	return bits.OnesCount64(uint64(m))
+}
+
+// String returns comma separated NUMA nodes such as "0,1".
+func (m NUMANodeMask) String() string {
+	nodes := make([]string, 0, m.Count())
+	for _, node := range m.Nodes() {
+		nodes = append(nodes, strconv.Itoa(node))
+	}
+
+	return strings.Join(nodes, ",")
+}
+
+// TopologyHint is NUMA affinity of a request, equivalent to the hint of Kubernetes Topology Manager.
+type TopologyHint struct {
+	NUMANodeAffinity NUMANodeMask
+	Preferred        bool
+}
+
+// GenerateTopologyHints lists every NUMANodeMask whose devices can satisfy the request of the given size.
+// Masks with the fewest NUMA nodes are marked as preferred. Devices without NUMA affinity are counted in every mask.
+// It returns nil if the request cannot be satisfied or no device reports NUMA affinity, which means no preference.
+func GenerateTopologyHints(available []furiosa_device.FuriosaDevice, size int) ([]TopologyHint, error) {
+	if size <= 0 || len(available) < size {
+		return nil, nil
+	}
+
+	countByNUMANode := make(map[int]int)
+	unknownCount := 0
+	for _, device := range available {
+		node := device.NUMANode()
+		if node < 0 {
+			unknownCount++
+			continue
+		}
+
+		if node >= maxNUMANodes {
+			return nil, fmt.Errorf("numa node %d of device %s is out of range [0, %d)", node, device.DeviceID(), maxNUMANodes)
+		}
+
+		countByNUMANode[node]++
+	}
+
+	nodes := make([]int, 0, len(countByNUMANode))
+	for node := range countByNUMANode {
+		nodes = append(nodes, node)
+	}
+
+	sort.Ints(nodes)
+
+	var hints []TopologyHint
+	minCount := maxNUMANodes + 1
+	for subset := 1; subset < 1<<len(nodes); subset++ {
+		mask := NUMANodeMask(0)
+		count := unknownCount
+		for i, node := range nodes {
+			if subset&(1<<i) != 0 {
+				mask |= 1 << node
+				count += countByNUMANode[node]
+			}
+		}
+
+		if count < size {
+			continue
+		}
+
+		hints = append(hints, TopologyHint{NUMANodeAffinity: mask})
+		minCount = min(minCount, mask.Count())
+	}
+
+	for i := range hints {
+		hints[i].Preferred = hints[i].NUMANodeAffinity.Count() == minCount
+	}
+
+	sort.Slice(hints, func(i, j int) bool {
+		if hints[i].NUMANodeAffinity.Count() != hints[j].NUMANodeAffinity.Count() {
+			return hints[i].NUMANodeAffinity.Count() < hints[j].NUMANodeAffinity.Count()
+		}
+
+		return hints[i].NUMANodeAffinity < hints[j].NUMANodeAffinity
+	})
+
+	return hints, nil
+}
@@ -0,0 +1,105 @@
+package npu_allocator
+
+import (
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+	"github.com/stretchr/testify/assert"
+)
+
+// numaOverriddenDevice overrides NUMA node of the embedded FuriosaDevice.
+type numaOverriddenDevice struct {
+	furiosa_device.FuriosaDevice
+	numaNode int
+}
+
+func (d *numaOverriddenDevice) NUMANode() int {
+	return d.numaNode
+}
+
+func mustNUMANodeMask(t *testing.T, nodes ...int) NUMANodeMask {
+	mask, err := NewNUMANodeMask(nodes...)
+	assert.NoError(t, err)
+
+	return mask
+}
+
+func TestNUMANodeMask(t *testing.T) {
+	mask := mustNUMANodeMask(t, 3, 0)
+
+	assert.Equal(t, []int{0, 3}, mask.Nodes())
+	assert.Equal(t, 2, mask.Count())
+	assert.Equal(t, "0,3", mask.String())
+	assert.True(t, mask.Has(3))
+	assert.False(t, mask.Has(1))
+
+	_, err := NewNUMANodeMask(64)
+	assert.Error(t, err)
+}
+
+func TestGenerateTopologyHints(t *testing.T) {
+	smiDevices := smi.GetStaticMockDevices(smi.ArchRngd)
+
+	furiosaDevices, err := furiosa_device.NewFuriosaDevices(smiDevices, nil, furiosa_device.NonePolicy)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		description string
+		available   []furiosa_device.FuriosaDevice
+		size        int
+		expected    []TopologyHint
+	}{
+		{
+			description: "request fits in a single numa node",
+			available:   furiosaDevices,
+			size:        2,
+			expected: []TopologyHint{
+				{NUMANodeAffinity: mustNUMANodeMask(t, 0), Preferred: true},
+				{NUMANodeAffinity: mustNUMANodeMask(t, 1), Preferred: true},
+				{NUMANodeAffinity: mustNUMANodeMask(t, 0, 1), Preferred: false},
+			},
+		},
+		{
+			description: "request fits in only one numa node",
+			available:   append(append([]furiosa_device.FuriosaDevice{}, furiosaDevices[:2]...), furiosaDevices[4:]...),
+			size:        3,
+			expected: []TopologyHint{
+				{NUMANodeAffinity: mustNUMANodeMask(t, 1), Preferred: true},
+				{NUMANodeAffinity: mustNUMANodeMask(t, 0, 1), Preferred: false},
+			},
+		},
+		{
+			description: "request spans numa nodes",
+			available:   furiosaDevices,
+			size:        6,
+			expected: []TopologyHint{
+				{NUMANodeAffinity: mustNUMANodeMask(t, 0, 1), Preferred: true},
+			},
+		},
+		{
+			description: "device without numa affinity is counted in every mask",
+			available:   []furiosa_device.FuriosaDevice{furiosaDevices[0], &numaOverriddenDevice{FuriosaDevice: furiosaDevices[1], numaNode: -1}, furiosaDevices[4]},
+			size:        2,
+			expected: []TopologyHint{
+				{NUMANodeAffinity: mustNUMANodeMask(t, 0), Preferred: true},
+				{NUMANodeAffinity: mustNUMANodeMask(t, 1), Preferred: true},
+				{NUMANodeAffinity: mustNUMANodeMask(t, 0, 1), Preferred: false},
+			},
+		},
+		{
+			description: "request more than available devices",
+			available:   furiosaDevices[:2],
+			size:        3,
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			actual, err := GenerateTopologyHints(tc.available, tc.size)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package npu_allocator
+
+import (
+	"fmt"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/util"
+)
+
+var _ NpuAllocator = (*pipelineNpuAllocator)(nil)
+
+// PluginConfig configures a plugin of the pipeline by its registered name.
+type PluginConfig struct {
+	Name string `json:"name"`
+
+	// Weight multiplies the score of a score plugin. Zero is treated as one.
+	Weight uint `json:"weight,omitempty"`
+
+	// Args are plugin specific arguments.
+	Args map[string]string `json:"args,omitempty"`
+}
+
+// PipelineConfig describes an allocation pipeline, which filters candidates, scores device sets and selects one of them.
+type PipelineConfig struct {
+	Filters []PluginConfig `json:"filters,omitempty"`
+	Scores  []PluginConfig `json:"scores,omitempty"`
+	Select  string         `json:"select"`
+}
+
+// FilterPlugin decides whether a device can be a candidate of allocation.
+type FilterPlugin interface {
+	Name() string
+	Filter(device Device) bool
+}
+
+// ScorePlugin scores a set of devices selected from the available devices. Higher is better.
+type ScorePlugin interface {
+	Name() string
+	Score(available DeviceSet, selected DeviceSet) int64
+}
+
+// DeviceSetScorer returns the weighted sum of score plugins for the selected devices.
+type DeviceSetScorer func(selected DeviceSet) int64
+
+// SelectStrategy selects `size` devices from candidates, including all required devices.
+type SelectStrategy interface {
+	Name() string
+	Select(candidates DeviceSet, required DeviceSet, size int, scorer DeviceSetScorer) DeviceSet
+}
+
+// PluginHandle provides information of devices to plugins.
+type PluginHandle struct {
+	// FuriosaDevices is keyed by DeviceID.
+	FuriosaDevices map[string]furiosa_device.FuriosaDevice
+
+	// SmiDevices is keyed by TopologyHintKey.
+	SmiDevices map[TopologyHintKey]smi.Device
+
+	TopologyHintMatrix TopologyHintMatrix
+}
+
+// NewPluginHandle builds PluginHandle from smi.Device and furiosa_device.FuriosaDevice.
+func NewPluginHandle(smiDevices []smi.Device, furiosaDevices []furiosa_device.FuriosaDevice, opts ...AllocatorOption) (*PluginHandle, error) {
+	options := newAllocatorOptions(opts...)
+
+	topologyHintMatrix, err := NewTopologyHintMatrixWithScoringPolicy(smiDevices, options.scoringPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	handle := &PluginHandle{
+		FuriosaDevices:     make(map[string]furiosa_device.FuriosaDevice, len(furiosaDevices)),
+		SmiDevices:         make(map[TopologyHintKey]smi.Device, len(smiDevices)),
+		TopologyHintMatrix: topologyHintMatrix,
+	}
+
+	for _, furiosaDevice := range furiosaDevices {
+		handle.FuriosaDevices[furiosaDevice.DeviceID()] = furiosaDevice
+	}
+
+	for _, smiDevice := range smiDevices {
+		deviceInfo, err := smiDevice.DeviceInfo()
+		if err != nil {
+			return nil, err
+		}
+
+		pciBusID, err := util.ParseBusIDFromBDF(deviceInfo.BDF())
+		if err != nil {
+			return nil, err
+		}
+
+		handle.SmiDevices[TopologyHintKey(pciBusID)] = smiDevice
+	}
+
+	return handle, nil
+}
+
+type FilterPluginFactory func(handle *PluginHandle, args map[string]string) (FilterPlugin, error)
+
+type ScorePluginFactory func(handle *PluginHandle, args map[string]string) (ScorePlugin, error)
+
+type SelectStrategyFactory func() SelectStrategy
+
+// PluginRegistry holds factories of plugins by name.
+type PluginRegistry struct {
+	filters    map[string]FilterPluginFactory
+	scores     map[string]ScorePluginFactory
+	strategies map[string]SelectStrategyFactory
+}
+
+// NewPluginRegistry returns an empty PluginRegistry.
+func NewPluginRegistry() *PluginRegistry {
+	return &PluginRegistry{
+		filters:    make(map[string]FilterPluginFactory),
+		scores:     make(map[string]ScorePluginFactory),
+		strategies: make(map[string]SelectStrategyFactory),
+	}
+}
+
+// NewDefaultPluginRegistry returns PluginRegistry with built-in plugins.
+func NewDefaultPluginRegistry() *PluginRegistry {
+	registry := NewPluginRegistry()
+
+	registry.RegisterFilter(HealthFilterName, newHealthFilter)
+	registry.RegisterFilter(NUMAFilterName, newNUMAFilter)
+	registry.RegisterFilter(BlockedFilterName, newBlockedFilter)
+	registry.RegisterFilter(LiveStateFilterName, newLiveStateFilterPlugin)
+
+	registry.RegisterScore(TopologyScoreName, newTopologyScore)
+	registry.RegisterScore(TemperatureScoreName, newTemperatureScore)
+	registry.RegisterScore(FragmentationScoreName, newFragmentationScore)
+
+	registry.RegisterSelectStrategy(GreedySelectName, newGreedySelect)
+	registry.RegisterSelectStrategy(ExhaustiveSelectName, newExhaustiveSelect)
+
+	return registry
+}
+
+func (r *PluginRegistry) RegisterFilter(name string, factory FilterPluginFactory) {
+	r.filters[name] = factory
+}
+
+func (r *PluginRegistry) RegisterScore(name string, factory ScorePluginFactory) {
+	r.scores[name] = factory
+}
+
+func (r *PluginRegistry) RegisterSelectStrategy(name string, factory SelectStrategyFactory) {
+	r.strategies[name] = factory
+}
+
+type weightedScorePlugin struct {
+	plugin ScorePlugin
+	weight int64
+}
+
+type pipelineNpuAllocator struct {
+	filters  []FilterPlugin
+	scores   []weightedScorePlugin
+	strategy SelectStrategy
+}
+
+// NewPipelineNpuAllocator assembles NpuAllocator from plugins of the registry, configured by PipelineConfig.
+func NewPipelineNpuAllocator(config PipelineConfig, handle *PluginHandle, registry *PluginRegistry) (NpuAllocator, error) {
+	pipeline := &pipelineNpuAllocator{}
+
+	for _, filterConfig := range config.Filters {
+		factory, ok := registry.filters[filterConfig.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter plugin %q", filterConfig.Name)
+		}
+
+		filter, err := factory(handle, filterConfig.Args)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create filter plugin %q: %w", filterConfig.Name, err)
+		}
+
+		pipeline.filters = append(pipeline.filters, filter)
+	}
+
+	for _, scoreConfig := range config.Scores {
+		factory, ok := registry.scores[scoreConfig.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown score plugin %q", scoreConfig.Name)
+		}
+
+		score, err := factory(handle, scoreConfig.Args)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create score plugin %q: %w", scoreConfig.Name, err)
+		}
+
+		weight := int64(scoreConfig.Weight)
+		if weight == 0 {
+			weight = 1
+		}
+
+		pipeline.scores = append(pipeline.scores, weightedScorePlugin{plugin: score, weight: weight})
+	}
+
+	factory, ok := registry.strategies[config.Select]
+	if !ok {
+		return nil, fmt.Errorf("unknown select strategy %q", config.Select)
+	}
+
+	pipeline.strategy = factory()
+
+	return pipeline, nil
+}
+
+// Allocate filters available devices, and selects devices with the highest weighted score.
+// Required devices are never filtered out. It returns empty DeviceSet if there are not enough candidates.
+func (p *pipelineNpuAllocator) Allocate(available DeviceSet, required DeviceSet, size int) DeviceSet {
+	// If length of `required` already satisfies given `size`, just return it.
+	if required.Len() == size {
+		return required
+	}
+
+	candidates := NewDeviceSet(required.Devices()...)
+	for _, device := range available.Devices() {
+		if p.filter(device) {
+			candidates.Insert(device)
+		}
+	}
+
+	if required.Len() > size || candidates.Len() < size {
+		return NewDeviceSet()
+	}
+
+	scorer := func(selected DeviceSet) int64 {
+		total := int64(0)
+		for _, score := range p.scores {
+			total += score.weight * score.plugin.Score(candidates, selected)
+		}
+
+		return total
+	}
+
+	return p.strategy.Select(candidates, required, size, scorer)
+}
+
+func (p *pipelineNpuAllocator) filter(device Device) bool {
+	for _, filter := range p.filters {
+		if !filter.Filter(device) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,317 @@
+package npu_allocator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+	"gonum.org/v1/gonum/stat/combin"
+)
+
+const (
+	HealthFilterName    = "health"
+	NUMAFilterName      = "numa"
+	BlockedFilterName   = "blocked"
+	LiveStateFilterName = "live-state"
+
+	TopologyScoreName      = "topology"
+	TemperatureScoreName   = "temperature"
+	FragmentationScoreName = "fragmentation"
+
+	GreedySelectName     = "greedy"
+	ExhaustiveSelectName = "exhaustive"
+
+	numaFilterNodesArg      = "nodes"
+	blockedFilterDevicesArg = "devices"
+)
+
+// splitArg splits comma separated argument, ignoring empty items.
+func splitArg(arg string) []string {
+	var items []string
+	for _, item := range strings.Split(arg, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// healthFilter drops unhealthy devices and devices unknown to PluginHandle.
+type healthFilter struct {
+	handle *PluginHandle
+}
+
+func newHealthFilter(handle *PluginHandle, _ map[string]string) (FilterPlugin, error) {
+	return &healthFilter{handle: handle}, nil
+}
+
+func (f *healthFilter) Name() string {
+	return HealthFilterName
+}
+
+func (f *healthFilter) Filter(device Device) bool {
+	furiosaDevice, ok := f.handle.FuriosaDevices[device.ID()]
+	if !ok {
+		return false
+	}
+
+	healthy, err := furiosaDevice.IsHealthy()
+
+	return err == nil && healthy
+}
+
+// numaFilter keeps devices attached to NUMA nodes given by "nodes" argument such as "0,1".
+type numaFilter struct {
+	handle *PluginHandle
+	nodes  map[int]struct{}
+}
+
+func newNUMAFilter(handle *PluginHandle, args map[string]string) (FilterPlugin, error) {
+	nodes := make(map[int]struct{})
+	for _, item := range splitArg(args[numaFilterNodesArg]) {
+		node, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse numa node %q: %w", item, err)
+		}
+
+		nodes[node] = struct{}{}
+	}
+
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("argument %q is required", numaFilterNodesArg)
+	}
+
+	return &numaFilter{handle: handle, nodes: nodes}, nil
+}
+
+func (f *numaFilter) Name() string {
+	return NUMAFilterName
+}
+
+func (f *numaFilter) Filter(device Device) bool {
+	furiosaDevice, ok := f.handle.FuriosaDevices[device.ID()]
+	if !ok {
+		return false
+	}
+
+	_, ok = f.nodes[furiosaDevice.NUMANode()]
+
+	return ok
+}
+
+// blockedFilter drops devices given by "devices" argument. Each item is either a device ID or UUID of a card.
+type blockedFilter struct {
+	blocked map[string]struct{}
+}
+
+func newBlockedFilter(_ *PluginHandle, args map[string]string) (FilterPlugin, error) {
+	blocked := make(map[string]struct{})
+	for _, item := range splitArg(args[blockedFilterDevicesArg]) {
+		blocked[item] = struct{}{}
+	}
+
+	return &blockedFilter{blocked: blocked}, nil
+}
+
+func (f *blockedFilter) Name() string {
+	return BlockedFilterName
+}
+
+func (f *blockedFilter) Filter(device Device) bool {
+	if _, ok := f.blocked[device.ID()]; ok {
+		return false
+	}
+
+	uuid, _, err := furiosa_device.ParseDeviceID(device.ID())
+	if err != nil {
+		return true
+	}
+
+	_, ok := f.blocked[uuid]
+
+	return !ok
+}
+
+// liveStateFilterPlugin adapts LiveStateFilter to FilterPlugin.
+type liveStateFilterPlugin struct {
+	filter LiveStateFilter
+}
+
+func newLiveStateFilterPlugin(handle *PluginHandle, _ map[string]string) (FilterPlugin, error) {
+	smiDevices := make([]smi.Device, 0, len(handle.SmiDevices))
+	for _, smiDevice := range handle.SmiDevices {
+		smiDevices = append(smiDevices, smiDevice)
+	}
+
+	filter, err := NewLiveStateFilter(smiDevices)
+	if err != nil {
+		return nil, err
+	}
+
+	return &liveStateFilterPlugin{filter: filter}, nil
+}
+
+func (f *liveStateFilterPlugin) Name() string {
+	return LiveStateFilterName
+}
+
+func (f *liveStateFilterPlugin) Filter(device Device) bool {
+	passed, _ := f.filter.Filter(NewDeviceSet(device))
+
+	return passed.Len() == 1
+}
+
+// topologyScore is the sum of TopologyHintMatrix scores of every pair of selected devices.
+type topologyScore struct {
+	calculator TopologyScoreCalculator
+}
+
+func newTopologyScore(handle *PluginHandle, _ map[string]string) (ScorePlugin, error) {
+	return &topologyScore{calculator: generateTopologyScoreCalculator(handle.TopologyHintMatrix)}, nil
+}
+
+func (s *topologyScore) Name() string {
+	return TopologyScoreName
+}
+
+func (s *topologyScore) Score(_ DeviceSet, selected DeviceSet) int64 {
+	keys := make([]TopologyHintKey, 0, selected.Len())
+	for _, device := range selected.Devices() {
+		keys = append(keys, device.TopologyHintKey())
+	}
+
+	return int64(s.calculator(keys))
+}
+
+// temperatureScore prefers cooler cards. It is the negative sum of SoC peak temperatures of selected cards.
+// Cards whose temperature couldn't be read don't affect the score.
+type temperatureScore struct {
+	handle *PluginHandle
+}
+
+func newTemperatureScore(handle *PluginHandle, _ map[string]string) (ScorePlugin, error) {
+	return &temperatureScore{handle: handle}, nil
+}
+
+func (s *temperatureScore) Name() string {
+	return TemperatureScoreName
+}
+
+func (s *temperatureScore) Score(_ DeviceSet, selected DeviceSet) int64 {
+	score := int64(0)
+	for _, key := range uniqueHintKeys(selected.Devices()) {
+		smiDevice, ok := s.handle.SmiDevices[key]
+		if !ok {
+			continue
+		}
+
+		temperature, err := smiDevice.DeviceTemperature()
+		if err != nil {
+			continue
+		}
+
+		score -= int64(temperature.SocPeak())
+	}
+
+	return score
+}
+
+// fragmentationScore prefers filling up partially used cards.
+// It is the negative number of devices left available on the cards touched by the selection.
+type fragmentationScore struct{}
+
+func newFragmentationScore(_ *PluginHandle, _ map[string]string) (ScorePlugin, error) {
+	return &fragmentationScore{}, nil
+}
+
+func (s *fragmentationScore) Name() string {
+	return FragmentationScoreName
+}
+
+func (s *fragmentationScore) Score(available DeviceSet, selected DeviceSet) int64 {
+	touched := make(map[TopologyHintKey]struct{})
+	for _, device := range selected.Devices() {
+		touched[device.TopologyHintKey()] = struct{}{}
+	}
+
+	left := int64(0)
+	for _, device := range available.Devices() {
+		if _, ok := touched[device.TopologyHintKey()]; ok && !selected.Contains(device) {
+			left++
+		}
+	}
+
+	return -left
+}
+
+// greedySelect adds a device maximizing the score one by one. Ties are broken by the order of candidates.
+type greedySelect struct{}
+
+func newGreedySelect() SelectStrategy {
+	return &greedySelect{}
+}
+
+func (s *greedySelect) Name() string {
+	return GreedySelectName
+}
+
+func (s *greedySelect) Select(candidates DeviceSet, required DeviceSet, size int, scorer DeviceSetScorer) DeviceSet {
+	selected := NewDeviceSet(required.Devices()...)
+	rest := candidates.Difference(required.Devices()...)
+
+	for selected.Len() < size && rest.Len() > 0 {
+		var bestDevice Device
+		var bestScore int64
+		for _, candidate := range rest.Devices() {
+			score := scorer(selected.Union(candidate))
+			if bestDevice == nil || score > bestScore {
+				bestDevice = candidate
+				bestScore = score
+			}
+		}
+
+		selected.Insert(bestDevice)
+		rest = rest.Difference(bestDevice)
+	}
+
+	return selected
+}
+
+// exhaustiveSelect scores every combination of candidates and selects the best one. Ties are broken by the order of combinations.
+type exhaustiveSelect struct{}
+
+func newExhaustiveSelect() SelectStrategy {
+	return &exhaustiveSelect{}
+}
+
+func (s *exhaustiveSelect) Name() string {
+	return ExhaustiveSelectName
+}
+
+func (s *exhaustiveSelect) Select(candidates DeviceSet, required DeviceSet, size int, scorer DeviceSetScorer) DeviceSet {
+	rest := candidates.Difference(required.Devices()...).Devices()
+	k := size - required.Len()
+	if k <= 0 || k > len(rest) {
+		return required
+	}
+
+	var best DeviceSet
+	var bestScore int64
+	for _, combination := range combin.Combinations(len(rest), k) {
+		selected := NewDeviceSet(required.Devices()...)
+		for _, idx := range combination {
+			selected.Insert(rest[idx])
+		}
+
+		score := scorer(selected)
+		if best == nil || score > bestScore {
+			best = selected
+			bestScore = score
+		}
+	}
+
+	return best
+}
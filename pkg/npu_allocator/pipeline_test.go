@@ -0,0 +1,197 @@
+package npu_allocator
+
+import (
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockDeviceTemperature struct {
+	socPeak float64
+}
+
+func (m *mockDeviceTemperature) SocPeak() float64 {
+	return m.socPeak
+}
+
+func (m *mockDeviceTemperature) Ambient() float64 {
+	return m.socPeak
+}
+
+// temperatureOverriddenDevice overrides temperature of the embedded smi.Device.
+type temperatureOverriddenDevice struct {
+	smi.Device
+	socPeak float64
+}
+
+func (d *temperatureOverriddenDevice) DeviceTemperature() (smi.DeviceTemperature, error) {
+	return &mockDeviceTemperature{socPeak: d.socPeak}, nil
+}
+
+func TestPipelineNpuAllocatorWithSmiDevices(t *testing.T) {
+	smiDevices := smi.GetStaticMockDevices(smi.ArchRngd)
+	blockedUUID := "A76AAD68-6855-40B1-9E86-D080852D1C80"
+
+	furiosaDevices, err := furiosa_device.NewFuriosaDevices(smiDevices, []string{blockedUUID}, furiosa_device.NonePolicy)
+	assert.NoError(t, err)
+
+	handle, err := NewPluginHandle(smiDevices, furiosaDevices)
+	assert.NoError(t, err)
+
+	available := NewDeviceSet()
+	for _, furiosaDevice := range furiosaDevices {
+		available.Insert(NewDevice(furiosaDevice))
+	}
+
+	topologyScore := PluginConfig{Name: TopologyScoreName}
+
+	tests := []struct {
+		description      string
+		config           PipelineConfig
+		size             int
+		expectedHintKeys []TopologyHintKey
+	}{
+		{
+			description:      "topology score with greedy select",
+			config:           PipelineConfig{Scores: []PluginConfig{topologyScore}, Select: GreedySelectName},
+			size:             2,
+			expectedHintKeys: []TopologyHintKey{"27", "2a"},
+		},
+		{
+			description: "numa filter with exhaustive select",
+			config: PipelineConfig{
+				Filters: []PluginConfig{{Name: NUMAFilterName, Args: map[string]string{"nodes": "1"}}},
+				Scores:  []PluginConfig{topologyScore},
+				Select:  ExhaustiveSelectName,
+			},
+			size:             2,
+			expectedHintKeys: []TopologyHintKey{"9e", "a4"},
+		},
+		{
+			description: "health filter drops blocked card",
+			config: PipelineConfig{
+				Filters: []PluginConfig{{Name: HealthFilterName}},
+				Scores:  []PluginConfig{topologyScore},
+				Select:  ExhaustiveSelectName,
+			},
+			size:             2,
+			expectedHintKeys: []TopologyHintKey{"51", "57"},
+		},
+		{
+			description: "blocked filter drops card by uuid",
+			config: PipelineConfig{
+				Filters: []PluginConfig{{Name: BlockedFilterName, Args: map[string]string{"devices": "A76AAD68-6855-40B1-9E86-D080852D1C84"}}},
+				Scores:  []PluginConfig{topologyScore},
+				Select:  GreedySelectName,
+			},
+			size:             5,
+			expectedHintKeys: []TopologyHintKey{"27", "2a", "51", "57", "a4"},
+		},
+		{
+			description: "not enough candidates",
+			config: PipelineConfig{
+				Filters: []PluginConfig{{Name: NUMAFilterName, Args: map[string]string{"nodes": "0"}}},
+				Select:  GreedySelectName,
+			},
+			size:             5,
+			expectedHintKeys: []TopologyHintKey{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			sut, err := NewPipelineNpuAllocator(tc.config, handle, NewDefaultPluginRegistry())
+			assert.NoError(t, err)
+
+			actual := sut.Allocate(available, NewDeviceSet(), tc.size)
+
+			hintKeys := make([]TopologyHintKey, 0, actual.Len())
+			for _, device := range actual.Devices() {
+				hintKeys = append(hintKeys, device.TopologyHintKey())
+			}
+
+			assert.Equal(t, tc.expectedHintKeys, hintKeys)
+		})
+	}
+}
+
+func TestPipelineNpuAllocatorScores(t *testing.T) {
+	smiDevices := smi.GetStaticMockDevices(smi.ArchRngd)
+
+	t.Run("fragmentation score fills up partially used card", func(t *testing.T) {
+		busyCard := generateSameBoardMockDeviceSet(0, 2, "0")
+		idleCard := generateSameBoardMockDeviceSet(1, 4, "1")
+
+		sut, err := NewPipelineNpuAllocator(PipelineConfig{
+			Scores: []PluginConfig{{Name: FragmentationScoreName}},
+			Select: GreedySelectName,
+		}, &PluginHandle{}, NewDefaultPluginRegistry())
+		assert.NoError(t, err)
+
+		actual := sut.Allocate(busyCard.Union(idleCard.Devices()...), NewDeviceSet(), 2)
+		assert.True(t, busyCard.Equal(actual.Devices()...))
+	})
+
+	t.Run("temperature score prefers cooler card", func(t *testing.T) {
+		handle := &PluginHandle{
+			SmiDevices: map[TopologyHintKey]smi.Device{
+				"0": &temperatureOverriddenDevice{Device: smiDevices[0], socPeak: 80},
+				"1": &temperatureOverriddenDevice{Device: smiDevices[1], socPeak: 40},
+			},
+		}
+
+		sut, err := NewPipelineNpuAllocator(PipelineConfig{
+			Scores: []PluginConfig{{Name: TemperatureScoreName, Weight: 2}},
+			Select: ExhaustiveSelectName,
+		}, handle, NewDefaultPluginRegistry())
+		assert.NoError(t, err)
+
+		actual := sut.Allocate(buildMockDeviceSet(0, 1), NewDeviceSet(), 1)
+		assert.True(t, NewDeviceSet(buildMockDevice(1)).Equal(actual.Devices()...))
+	})
+
+	t.Run("required devices are kept", func(t *testing.T) {
+		sut, err := NewPipelineNpuAllocator(PipelineConfig{
+			Filters: []PluginConfig{{Name: BlockedFilterName, Args: map[string]string{"devices": "3"}}},
+			Scores:  []PluginConfig{{Name: TopologyScoreName}},
+			Select:  GreedySelectName,
+		}, &PluginHandle{TopologyHintMatrix: buildStaticHintMatrixForTwoSocketBalancedConfig()}, NewDefaultPluginRegistry())
+		assert.NoError(t, err)
+
+		actual := sut.Allocate(buildMockDeviceSet(0, 7), NewDeviceSet(buildMockDevice(3)), 2)
+		assert.True(t, NewDeviceSet(buildMockDevice(2), buildMockDevice(3)).Equal(actual.Devices()...))
+	})
+}
+
+func TestNewPipelineNpuAllocatorWithInvalidConfig(t *testing.T) {
+	tests := []struct {
+		description string
+		config      PipelineConfig
+	}{
+		{
+			description: "unknown filter",
+			config:      PipelineConfig{Filters: []PluginConfig{{Name: "unknown"}}, Select: GreedySelectName},
+		},
+		{
+			description: "unknown score",
+			config:      PipelineConfig{Scores: []PluginConfig{{Name: "unknown"}}, Select: GreedySelectName},
+		},
+		{
+			description: "unknown select strategy",
+			config:      PipelineConfig{Select: "unknown"},
+		},
+		{
+			description: "numa filter without nodes",
+			config:      PipelineConfig{Filters: []PluginConfig{{Name: NUMAFilterName}}, Select: GreedySelectName},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := NewPipelineNpuAllocator(tc.config, &PluginHandle{}, NewDefaultPluginRegistry())
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package npu_allocator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+)
+
+var _ PreemptionPlanner = (*preemptionPlanner)(nil)
+
+// maxExhaustiveVictims is the number of preemptible entries up to which every subset of victims is evaluated.
+const maxExhaustiveVictims = 16
+
+// LedgerEntry is a current allocation of an owner, such as a pod.
+type LedgerEntry struct {
+	Owner    string
+	Priority int
+	Devices  DeviceSet
+}
+
+// PreemptionPlan describes victims to evict, and devices to allocate after eviction.
+type PreemptionPlan struct {
+	Victims []LedgerEntry
+	Devices DeviceSet
+	Score   uint
+
+	// Cost is the number of devices of victims.
+	Cost int
+}
+
+// PreemptionPlanner plans preemption to make a locality-optimal device set available.
+type PreemptionPlanner interface {
+	// Plan returns victims with the minimal cost among those making the best achievable score for `size` devices available.
+	// Only entries with lower priority than the given priority can be victims.
+	// Ties of cost are broken by the lower sum of victims' priorities, and then by the fewer victims.
+	Plan(available DeviceSet, ledger []LedgerEntry, size int, priority int) (*PreemptionPlan, error)
+}
+
+type preemptionPlanner struct {
+	capacityPlanner *capacityPlanner
+}
+
+func NewPreemptionPlanner(devices []smi.Device, opts ...AllocatorOption) (PreemptionPlanner, error) {
+	options := newAllocatorOptions(opts...)
+
+	topologyHintMatrix, err := NewTopologyHintMatrixWithScoringPolicy(devices, options.scoringPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPreemptionPlanner(topologyHintMatrix), nil
+}
+
+func NewMockPreemptionPlanner(topologyHintMatrix TopologyHintMatrix) (PreemptionPlanner, error) {
+	return newPreemptionPlanner(topologyHintMatrix), nil
+}
+
+func newPreemptionPlanner(topologyHintMatrix TopologyHintMatrix) PreemptionPlanner {
+	return &preemptionPlanner{capacityPlanner: &capacityPlanner{topologyHintMatrix: topologyHintMatrix}}
+}
+
+// victimCandidate is a combination of victims with its cost.
+type victimCandidate struct {
+	victims     []LedgerEntry
+	cost        int
+	prioritySum int
+}
+
+func newVictimCandidate(victims []LedgerEntry) *victimCandidate {
+	candidate := &victimCandidate{victims: victims}
+	for _, victim := range victims {
+		candidate.cost += victim.Devices.Len()
+		candidate.prioritySum += victim.Priority
+	}
+
+	return candidate
+}
+
+func (v *victimCandidate) cheaperThan(other *victimCandidate) bool {
+	if other == nil {
+		return true
+	}
+
+	if v.cost != other.cost {
+		return v.cost < other.cost
+	}
+
+	if v.prioritySum != other.prioritySum {
+		return v.prioritySum < other.prioritySum
+	}
+
+	return len(v.victims) < len(other.victims)
+}
+
+// score returns the best achievable score after evicting victims.
+func (p *preemptionPlanner) score(available DeviceSet, victims []LedgerEntry, size int) (uint, bool) {
+	pool := available
+	for _, victim := range victims {
+		pool = pool.Union(victim.Devices.Devices()...)
+	}
+
+	return p.capacityPlanner.BestAchievableScore(pool, size)
+}
+
+func (p *preemptionPlanner) Plan(available DeviceSet, ledger []LedgerEntry, size int, priority int) (*PreemptionPlan, error) {
+	preemptible := make([]LedgerEntry, 0)
+	for _, entry := range ledger {
+		if entry.Priority < priority {
+			preemptible = append(preemptible, entry)
+		}
+	}
+
+	// sort by cost, so that cheaper victims are considered first.
+	sort.SliceStable(preemptible, func(i, j int) bool {
+		return newVictimCandidate(preemptible[i : i+1]).cheaperThan(newVictimCandidate(preemptible[j : j+1]))
+	})
+
+	targetScore, ok := p.score(available, preemptible, size)
+	if !ok {
+		return nil, fmt.Errorf("couldn't make %d devices available even with preemption", size)
+	}
+
+	var best *victimCandidate
+	if len(preemptible) <= maxExhaustiveVictims {
+		for subset := 0; subset < 1<<len(preemptible); subset++ {
+			victims := make([]LedgerEntry, 0)
+			for idx, entry := range preemptible {
+				if subset&(1<<idx) != 0 {
+					victims = append(victims, entry)
+				}
+			}
+
+			candidate := newVictimCandidate(victims)
+			if !candidate.cheaperThan(best) {
+				continue
+			}
+
+			if score, ok := p.score(available, victims, size); ok && score >= targetScore {
+				best = candidate
+			}
+		}
+	} else {
+		best = p.greedyVictims(available, preemptible, size, targetScore)
+	}
+
+	return p.buildPlan(available, best, size), nil
+}
+
+// greedyVictims adds cheaper victims until the target score is achieved, and then drops unnecessary victims.
+func (p *preemptionPlanner) greedyVictims(available DeviceSet, preemptible []LedgerEntry, size int, targetScore uint) *victimCandidate {
+	victims := make([]LedgerEntry, 0)
+	for _, entry := range preemptible {
+		if score, ok := p.score(available, victims, size); ok && score >= targetScore {
+			break
+		}
+
+		victims = append(victims, entry)
+	}
+
+	for idx := len(victims) - 1; idx >= 0; idx-- {
+		reduced := append(append(make([]LedgerEntry, 0, len(victims)-1), victims[:idx]...), victims[idx+1:]...)
+		if score, ok := p.score(available, reduced, size); ok && score >= targetScore {
+			victims = reduced
+		}
+	}
+
+	return newVictimCandidate(victims)
+}
+
+// buildPlan picks devices achieving the best score, preferring available devices over devices of victims.
+func (p *preemptionPlanner) buildPlan(available DeviceSet, victims *victimCandidate, size int) *PreemptionPlan {
+	pool := available
+	for _, victim := range victims.victims {
+		pool = pool.Union(victim.Devices.Devices()...)
+	}
+
+	keys, counts := countByHintKey(pool)
+	bestCounts, score, _ := p.capacityPlanner.bestCounts(keys, counts, size)
+
+	devicesByHintKey := make(map[TopologyHintKey][]Device)
+	for _, device := range available.Devices() {
+		devicesByHintKey[device.TopologyHintKey()] = append(devicesByHintKey[device.TopologyHintKey()], device)
+	}
+
+	for _, device := range pool.Difference(available.Devices()...).Devices() {
+		devicesByHintKey[device.TopologyHintKey()] = append(devicesByHintKey[device.TopologyHintKey()], device)
+	}
+
+	devices := NewDeviceSet()
+	for idx, key := range keys {
+		devices.Insert(devicesByHintKey[key][:bestCounts[idx]]...)
+	}
+
+	return &PreemptionPlan{
+		Victims: victims.victims,
+		Devices: devices,
+		Score:   score,
+		Cost:    victims.cost,
+	}
+}
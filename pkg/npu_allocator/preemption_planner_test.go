@@ -0,0 +1,111 @@
+package npu_allocator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreemptionPlanner(t *testing.T) {
+	entryA := LedgerEntry{Owner: "a", Priority: 1, Devices: NewDeviceSet(buildMockDevice(0), buildMockDevice(1))}
+	entryB := LedgerEntry{Owner: "b", Priority: 5, Devices: NewDeviceSet(buildMockDevice(2))}
+	entryC := LedgerEntry{Owner: "c", Priority: 20, Devices: NewDeviceSet(buildMockDevice(3))}
+	entryD := LedgerEntry{Owner: "d", Priority: 1, Devices: NewDeviceSet(buildMockDevice(4), buildMockDevice(5), buildMockDevice(6))}
+	entryE := LedgerEntry{Owner: "e", Priority: 3, Devices: NewDeviceSet(buildMockDevice(6), buildMockDevice(7))}
+
+	tests := []struct {
+		description     string
+		available       DeviceSet
+		ledger          []LedgerEntry
+		size            int
+		expectedOwners  []string
+		expectedDevices DeviceSet
+		expectedScore   uint
+		expectedCost    int
+		expectError     bool
+	}{
+		{
+			description:     "evict the owner blocking the tightest socket",
+			available:       NewDeviceSet(buildMockDevice(7)),
+			ledger:          []LedgerEntry{entryA, entryB, entryC, entryD},
+			size:            4,
+			expectedOwners:  []string{"d"},
+			expectedDevices: buildMockDeviceSet(4, 7),
+			expectedScore:   140,
+			expectedCost:    3,
+		},
+		{
+			description:     "no preemption is needed",
+			available:       buildMockDeviceSet(0, 3),
+			ledger:          []LedgerEntry{entryD},
+			size:            4,
+			expectedOwners:  []string{},
+			expectedDevices: buildMockDeviceSet(0, 3),
+			expectedScore:   140,
+			expectedCost:    0,
+		},
+		{
+			description:     "lower priority victim is preferred on the same cost",
+			available:       NewDeviceSet(),
+			ledger:          []LedgerEntry{entryE, entryA},
+			size:            2,
+			expectedOwners:  []string{"a"},
+			expectedDevices: buildMockDeviceSet(0, 1),
+			expectedScore:   30,
+			expectedCost:    2,
+		},
+		{
+			description: "higher priority owner is never evicted",
+			available:   NewDeviceSet(buildMockDevice(7)),
+			ledger:      []LedgerEntry{entryC},
+			size:        2,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			sut, err := NewMockPreemptionPlanner(buildStaticHintMatrixForTwoSocketBalancedConfig())
+			assert.NoError(t, err)
+
+			actual, err := sut.Plan(tc.available, tc.ledger, tc.size, 10)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			owners := make([]string, 0, len(actual.Victims))
+			for _, victim := range actual.Victims {
+				owners = append(owners, victim.Owner)
+			}
+
+			assert.Equal(t, tc.expectedOwners, owners)
+			assert.Truef(t, tc.expectedDevices.Equal(actual.Devices.Devices()...), "expected %v but got %v", tc.expectedDevices.Devices(), actual.Devices.Devices())
+			assert.Equal(t, tc.expectedScore, actual.Score)
+			assert.Equal(t, tc.expectedCost, actual.Cost)
+		})
+	}
+}
+
+func TestPreemptionPlannerWithManyEntries(t *testing.T) {
+	ledger := make([]LedgerEntry, 0)
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 3; j++ {
+			ledger = append(ledger, LedgerEntry{
+				Owner:    string(rune('a'+i)) + string(rune('0'+j)),
+				Priority: 1,
+				Devices:  generateSameBoardMockDeviceSet(i, 1, buildMockDevice(i).TopologyHintKey()),
+			})
+		}
+	}
+
+	sut, err := NewMockPreemptionPlanner(buildStaticHintMatrixForTwoSocketBalancedConfig())
+	assert.NoError(t, err)
+
+	actual, err := sut.Plan(NewDeviceSet(), ledger, 2, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(70), actual.Score)
+	assert.Equal(t, 2, actual.Cost)
+}
@@ -0,0 +1,210 @@
+package npu_allocator
+
+import (
+	"fmt"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+)
+
+var _ ReservationAwareNpuAllocator = (*reservationAwareNpuAllocator)(nil)
+
+// DeviceSelector selects a card by one of UUID, BDF or index, and optionally a partition of the card such as "0-1".
+// If Partition is empty, every device of the card is selected.
+type DeviceSelector struct {
+	UUID      string `json:"uuid,omitempty"`
+	BDF       string `json:"bdf,omitempty"`
+	Index     *int   `json:"index,omitempty"`
+	Partition string `json:"partition,omitempty"`
+}
+
+func (s DeviceSelector) matches(deviceInfo smi.DeviceInfo) bool {
+	switch {
+	case s.UUID != "":
+		return s.UUID == deviceInfo.UUID()
+
+	case s.BDF != "":
+		return s.BDF == deviceInfo.BDF()
+
+	case s.Index != nil:
+		return *s.Index == int(deviceInfo.Index())
+
+	default:
+		return false
+	}
+}
+
+// ReservationPool is a named set of devices set aside for node-level system workloads such as monitoring and canaries.
+type ReservationPool struct {
+	Name      string           `json:"name"`
+	Selectors []DeviceSelector `json:"selectors"`
+}
+
+// Reservations holds devices of reservation pools.
+type Reservations struct {
+	pools    map[string]DeviceSet
+	reserved DeviceSet
+}
+
+// NewReservations resolves selectors of the pools into devices. It fails if a selector matches no device,
+// or a device belongs to more than one pool.
+func NewReservations(smiDevices []smi.Device, furiosaDevices []furiosa_device.FuriosaDevice, pools ...ReservationPool) (*Reservations, error) {
+	deviceInfos := make([]smi.DeviceInfo, 0, len(smiDevices))
+	for _, smiDevice := range smiDevices {
+		deviceInfo, err := smiDevice.DeviceInfo()
+		if err != nil {
+			return nil, err
+		}
+
+		deviceInfos = append(deviceInfos, deviceInfo)
+	}
+
+	reservations := &Reservations{
+		pools:    make(map[string]DeviceSet, len(pools)),
+		reserved: NewDeviceSet(),
+	}
+
+	for _, pool := range pools {
+		if _, ok := reservations.pools[pool.Name]; ok {
+			return nil, fmt.Errorf("reservation pool %q is duplicated", pool.Name)
+		}
+
+		devices := NewDeviceSet()
+		for _, selector := range pool.Selectors {
+			selected, err := selectDevices(selector, deviceInfos, furiosaDevices)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't resolve reservation pool %q: %w", pool.Name, err)
+			}
+
+			devices.Insert(selected...)
+		}
+
+		for _, device := range devices.Devices() {
+			if reservations.reserved.Contains(device) {
+				return nil, fmt.Errorf("device %s of reservation pool %q is already reserved by another pool", device.ID(), pool.Name)
+			}
+		}
+
+		reservations.pools[pool.Name] = devices
+		reservations.reserved.Insert(devices.Devices()...)
+	}
+
+	return reservations, nil
+}
+
+func selectDevices(selector DeviceSelector, deviceInfos []smi.DeviceInfo, furiosaDevices []furiosa_device.FuriosaDevice) ([]Device, error) {
+	var uuid string
+	for _, deviceInfo := range deviceInfos {
+		if selector.matches(deviceInfo) {
+			uuid = deviceInfo.UUID()
+			break
+		}
+	}
+
+	if uuid == "" {
+		return nil, fmt.Errorf("no card matches selector %+v", selector)
+	}
+
+	var partition *furiosa_device.Partition
+	if selector.Partition != "" {
+		parsed, err := furiosa_device.ParsePartition(selector.Partition)
+		if err != nil {
+			return nil, err
+		}
+
+		partition = &parsed
+	}
+
+	var selected []Device
+	for _, furiosaDevice := range furiosaDevices {
+		deviceUUID, devicePartition, err := furiosa_device.ParseDeviceID(furiosaDevice.DeviceID())
+		if err != nil {
+			return nil, err
+		}
+
+		if deviceUUID != uuid {
+			continue
+		}
+
+		if partition != nil && (devicePartition == nil || *devicePartition != *partition) {
+			continue
+		}
+
+		selected = append(selected, NewDevice(furiosaDevice))
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no device matches selector %+v", selector)
+	}
+
+	return selected, nil
+}
+
+// Pool returns devices of the named pool.
+func (r *Reservations) Pool(name string) (DeviceSet, bool) {
+	devices, ok := r.pools[name]
+
+	return devices, ok
+}
+
+// IsReserved checks whether the device belongs to any pool.
+func (r *Reservations) IsReserved(device Device) bool {
+	return r.reserved.Contains(device)
+}
+
+// ReservationAwareNpuAllocator never hands out reserved devices to ordinary requests.
+type ReservationAwareNpuAllocator interface {
+	NpuAllocator
+
+	// AllocateFromPool allocates devices only from the named reservation pool.
+	AllocateFromPool(pool string, available DeviceSet, required DeviceSet, size int) (DeviceSet, error)
+}
+
+type reservationAwareNpuAllocator struct {
+	allocator    NpuAllocator
+	reservations *Reservations
+}
+
+// NewReservationAwareNpuAllocator wraps the allocator with reservations.
+func NewReservationAwareNpuAllocator(allocator NpuAllocator, reservations *Reservations) ReservationAwareNpuAllocator {
+	return &reservationAwareNpuAllocator{
+		allocator:    allocator,
+		reservations: reservations,
+	}
+}
+
+// Allocate excludes reserved devices from available devices.
+// Reserved devices are still advertised, so the caller may pass them as required devices.
+// Such a request is refused with an empty DeviceSet, since ordinary requests must never get reserved devices.
+func (r *reservationAwareNpuAllocator) Allocate(available DeviceSet, required DeviceSet, size int) DeviceSet {
+	if required.Intersection(r.reservations.reserved.Devices()...).Len() > 0 {
+		return NewDeviceSet()
+	}
+
+	return r.allocator.Allocate(available.Difference(r.reservations.reserved.Devices()...), required, size)
+}
+
+func (r *reservationAwareNpuAllocator) AllocateFromPool(pool string, available DeviceSet, required DeviceSet, size int) (DeviceSet, error) {
+	poolDevices, ok := r.reservations.Pool(pool)
+	if !ok {
+		return nil, fmt.Errorf("unknown reservation pool %q", pool)
+	}
+
+	if required.Len() > 0 && !poolDevices.Contains(required.Devices()...) {
+		return nil, fmt.Errorf("required devices are not in reservation pool %q", pool)
+	}
+
+	candidates := NewDeviceSet()
+	for _, device := range available.Devices() {
+		if poolDevices.Contains(device) {
+			candidates.Insert(device)
+		}
+	}
+
+	allocated := r.allocator.Allocate(candidates, required, size)
+	if allocated.Len() != size {
+		return nil, fmt.Errorf("couldn't allocate %d devices from reservation pool %q", size, pool)
+	}
+
+	return allocated, nil
+}
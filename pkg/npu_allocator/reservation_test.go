@@ -0,0 +1,125 @@
+package npu_allocator
+
+import (
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservationAwareNpuAllocator(t *testing.T) {
+	smiDevices := smi.GetStaticMockDevices(smi.ArchRngd)
+
+	furiosaDevices, err := furiosa_device.NewFuriosaDevices(smiDevices, nil, furiosa_device.DualCorePolicy)
+	assert.NoError(t, err)
+
+	available := NewDeviceSet()
+	for _, furiosaDevice := range furiosaDevices {
+		available.Insert(NewDevice(furiosaDevice))
+	}
+
+	lastCardIndex := 7
+	reservations, err := NewReservations(smiDevices, furiosaDevices,
+		ReservationPool{
+			Name:      "monitoring",
+			Selectors: []DeviceSelector{{UUID: "A76AAD68-6855-40B1-9E86-D080852D1C80", Partition: "0-1"}},
+		},
+		ReservationPool{
+			Name: "canary",
+			Selectors: []DeviceSelector{
+				{BDF: "0000:2a:00.0"},
+				{Index: &lastCardIndex, Partition: "6-7"},
+			},
+		},
+	)
+	assert.NoError(t, err)
+
+	monitoring, ok := reservations.Pool("monitoring")
+	assert.True(t, ok)
+	assert.Equal(t, 1, monitoring.Len())
+
+	canary, ok := reservations.Pool("canary")
+	assert.True(t, ok)
+	assert.Equal(t, 5, canary.Len())
+
+	allocator, err := NewBinPackingNpuAllocator(smiDevices)
+	assert.NoError(t, err)
+
+	sut := NewReservationAwareNpuAllocator(allocator, reservations)
+
+	t.Run("ordinary request never gets reserved devices", func(t *testing.T) {
+		actual := sut.Allocate(available, NewDeviceSet(), 8)
+
+		assert.Equal(t, 8, actual.Len())
+		for _, device := range actual.Devices() {
+			assert.False(t, reservations.IsReserved(device))
+		}
+	})
+
+	t.Run("ordinary request requiring a reserved device is refused", func(t *testing.T) {
+		reservedDevice := monitoring.Devices()[0]
+		actual := sut.Allocate(available, NewDeviceSet(reservedDevice), 2)
+
+		assert.Equal(t, 0, actual.Len())
+	})
+
+	t.Run("allocate from named pool", func(t *testing.T) {
+		actual, err := sut.AllocateFromPool("canary", available, NewDeviceSet(), 2)
+		assert.NoError(t, err)
+		assert.True(t, canary.Contains(actual.Devices()...))
+		assert.Equal(t, []TopologyHintKey{"2a", "2a"}, deviceSetHintKeys(actual))
+	})
+
+	t.Run("pool is exhausted", func(t *testing.T) {
+		_, err := sut.AllocateFromPool("monitoring", available, NewDeviceSet(), 2)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown pool", func(t *testing.T) {
+		_, err := sut.AllocateFromPool("unknown", available, NewDeviceSet(), 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestNewReservationsWithInvalidPools(t *testing.T) {
+	smiDevices := smi.GetStaticMockDevices(smi.ArchRngd)
+
+	furiosaDevices, err := furiosa_device.NewFuriosaDevices(smiDevices, nil, furiosa_device.NonePolicy)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		description string
+		pools       []ReservationPool
+	}{
+		{
+			description: "device reserved by two pools",
+			pools: []ReservationPool{
+				{Name: "a", Selectors: []DeviceSelector{{BDF: "0000:27:00.0"}}},
+				{Name: "b", Selectors: []DeviceSelector{{UUID: "A76AAD68-6855-40B1-9E86-D080852D1C80"}}},
+			},
+		},
+		{
+			description: "selector matches no card",
+			pools:       []ReservationPool{{Name: "a", Selectors: []DeviceSelector{{BDF: "0000:ff:00.0"}}}},
+		},
+		{
+			description: "partition doesn't exist under the policy",
+			pools:       []ReservationPool{{Name: "a", Selectors: []DeviceSelector{{BDF: "0000:27:00.0", Partition: "0-1"}}}},
+		},
+		{
+			description: "duplicated pool name",
+			pools: []ReservationPool{
+				{Name: "a", Selectors: []DeviceSelector{{BDF: "0000:27:00.0"}}},
+				{Name: "a", Selectors: []DeviceSelector{{BDF: "0000:2a:00.0"}}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := NewReservations(smiDevices, furiosaDevices, tc.pools...)
+			assert.Error(t, err)
+		})
+	}
+}
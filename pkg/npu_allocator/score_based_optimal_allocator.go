@@ -8,16 +8,29 @@ import (
 var _ NpuAllocator = (*scoreBasedOptimalNpuAllocator)(nil)
 
 type scoreBasedOptimalNpuAllocator struct {
-	hintProvider TopologyHintProvider
+	hintProvider      TopologyHintProvider
+	thermalTieBreaker *thermalTieBreaker
 }
 
-func NewScoreBasedOptimalNpuAllocator(devices []smi.Device) (NpuAllocator, error) {
-	topologyHintMatrix, err := NewTopologyHintMatrix(devices)
+func NewScoreBasedOptimalNpuAllocator(devices []smi.Device, opts ...AllocatorOption) (NpuAllocator, error) {
+	options := newAllocatorOptions(opts...)
+
+	topologyHintMatrix, err := NewTopologyHintMatrixWithScoringPolicy(devices, options.scoringPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	tieBreaker, err := newThermalTieBreaker(options)
 	if err != nil {
 		return nil, err
 	}
 
-	hintProvider := func(device1, device2 Device) uint {
+	return newScoreBasedOptimalNpuAllocator(generateTopologyHintProvider(topologyHintMatrix), tieBreaker), nil
+}
+
+// generateTopologyHintProvider returns TopologyHintProvider that looks up score of two devices from the given TopologyHintMatrix.
+func generateTopologyHintProvider(topologyHintMatrix TopologyHintMatrix) TopologyHintProvider {
+	return func(device1, device2 Device) uint {
 		key1, key2 := device1.TopologyHintKey(), device2.TopologyHintKey()
 		if key1 > key2 {
 			key1, key2 = key2, key1
@@ -31,17 +44,21 @@ func NewScoreBasedOptimalNpuAllocator(devices []smi.Device) (NpuAllocator, error
 
 		return 0
 	}
-
-	return newScoreBasedOptimalNpuAllocator(hintProvider), nil
 }
 
-func NewMockScoreBasedOptimalNpuAllocator(mockHintProvider TopologyHintProvider) (NpuAllocator, error) {
-	return newScoreBasedOptimalNpuAllocator(mockHintProvider), nil
+func NewMockScoreBasedOptimalNpuAllocator(mockHintProvider TopologyHintProvider, opts ...AllocatorOption) (NpuAllocator, error) {
+	tieBreaker, err := newThermalTieBreaker(newAllocatorOptions(opts...))
+	if err != nil {
+		return nil, err
+	}
+
+	return newScoreBasedOptimalNpuAllocator(mockHintProvider, tieBreaker), nil
 }
 
-func newScoreBasedOptimalNpuAllocator(hintProvider TopologyHintProvider) NpuAllocator {
+func newScoreBasedOptimalNpuAllocator(hintProvider TopologyHintProvider, tieBreaker *thermalTieBreaker) NpuAllocator {
 	return &scoreBasedOptimalNpuAllocator{
-		hintProvider: hintProvider,
+		hintProvider:      hintProvider,
+		thermalTieBreaker: tieBreaker,
 	}
 }
 
@@ -56,6 +73,11 @@ func (n *scoreBasedOptimalNpuAllocator) Allocate(available DeviceSet, required D
 	difference := available.Difference(required.Devices()...)
 	combinations := generateKDeviceSet(difference, subsetLen)
 
+	// there is no combination if the request can't be satisfied with available devices.
+	if len(combinations) == 0 {
+		return NewDeviceSet()
+	}
+
 	// union subset and required to build full device set combination
 	for idx, combination := range combinations {
 		newDeviceSet := combination.Union(required.Devices()...)
@@ -64,13 +86,32 @@ func (n *scoreBasedOptimalNpuAllocator) Allocate(available DeviceSet, required D
 
 	// score all survived device set
 	// initialize with the first element to prevent edge case that score of all element in the filtered list is zero.
+	// ties are broken by the adjacency of core ranges within a card, and then by the lowest thermal penalty
+	// if the thermal tie breaker is enabled.
+	thermalPenaltyCalculator := n.thermalTieBreaker.snapshot()
+
 	var bestSet = combinations[0]
 	var highestScore = n.scoreDeviceSet(bestSet)
+	var highestAdjacency = coreAdjacencyScore(bestSet.Devices())
+	var lowestPenalty = thermalPenaltyCalculator(deviceSetHintKeys(bestSet))
 
 	for _, set := range combinations {
-		if score := n.scoreDeviceSet(set); score > highestScore {
+		score := n.scoreDeviceSet(set)
+		if score < highestScore {
+			continue
+		}
+
+		adjacency := coreAdjacencyScore(set.Devices())
+		if score > highestScore || adjacency > highestAdjacency {
 			bestSet = set
 			highestScore = score
+			highestAdjacency = adjacency
+			lowestPenalty = thermalPenaltyCalculator(deviceSetHintKeys(set))
+		} else if adjacency == highestAdjacency && n.thermalTieBreaker != nil {
+			if penalty := thermalPenaltyCalculator(deviceSetHintKeys(set)); penalty < lowestPenalty {
+				bestSet = set
+				lowestPenalty = penalty
+			}
 		}
 	}
 
@@ -99,6 +140,16 @@ func generateKDeviceSet(ds DeviceSet, size int) (result []DeviceSet) {
 	return result
 }
 
+// deviceSetHintKeys returns TopologyHintKeys of devices in the DeviceSet.
+func deviceSetHintKeys(deviceSet DeviceSet) []TopologyHintKey {
+	keys := make([]TopologyHintKey, 0, deviceSet.Len())
+	for _, device := range deviceSet.Devices() {
+		keys = append(keys, device.TopologyHintKey())
+	}
+
+	return keys
+}
+
 func (n *scoreBasedOptimalNpuAllocator) scoreDeviceSet(deviceSet DeviceSet) uint {
 	total := uint(0)
 
@@ -354,6 +354,14 @@ func TestAllocation(t *testing.T) {
 			hints:       buildStaticHintMatrixForTwoSocketBalancedConfig(),
 			expected:    buildMockDeviceSet(0, 3),
 		},
+		{
+			description: "[infeasible] request more devices than available",
+			available:   buildMockDeviceSet(0, 3),
+			required:    NewDeviceSet(),
+			request:     5,
+			hints:       buildStaticHintMatrixForTwoSocketBalancedConfig(),
+			expected:    NewDeviceSet(),
+		},
 	}
 
 	for _, tc := range tests {
@@ -0,0 +1,94 @@
+package npu_allocator
+
+import (
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+)
+
+var _ NpuAllocator = (*spreadNpuAllocator)(nil)
+
+// spreadNpuAllocator is the opposite of binPackingNpuAllocator.
+// It spreads devices of a request across NUMA nodes and PCIe switches to limit the blast radius and balance host bandwidth.
+type spreadNpuAllocator struct {
+	hintProvider TopologyHintProvider
+}
+
+func NewSpreadNpuAllocator(devices []smi.Device, opts ...AllocatorOption) (NpuAllocator, error) {
+	options := newAllocatorOptions(opts...)
+
+	topologyHintMatrix, err := NewTopologyHintMatrixWithScoringPolicy(devices, options.scoringPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSpreadNpuAllocator(generateTopologyHintProvider(topologyHintMatrix)), nil
+}
+
+func NewMockSpreadNpuAllocator(mockHintProvider TopologyHintProvider) (NpuAllocator, error) {
+	return newSpreadNpuAllocator(mockHintProvider), nil
+}
+
+func newSpreadNpuAllocator(hintProvider TopologyHintProvider) NpuAllocator {
+	return &spreadNpuAllocator{
+		hintProvider: hintProvider,
+	}
+}
+
+// Allocate greedily picks a device minimizing the sum of scores against already collected devices.
+// Ties are broken by the following order to keep per-card usage even.
+//   - fewer devices already collected from the same card.
+//   - more available devices remaining on the same card, which means the card is less used.
+//   - lower index.
+func (s *spreadNpuAllocator) Allocate(available DeviceSet, required DeviceSet, size int) DeviceSet {
+	// If length of `required` already satisfies given `size`, just return it.
+	if required.Len() == size {
+		return required
+	}
+
+	collectedDevices := NewDeviceSet(required.Devices()...)
+	collectedCountByHintKey := make(map[TopologyHintKey]int)
+	for _, device := range required.Devices() {
+		collectedCountByHintKey[device.TopologyHintKey()] += 1
+	}
+
+	candidates := available.Difference(required.Devices()...)
+	availableCountByHintKey := make(map[TopologyHintKey]int)
+	for _, device := range candidates.Devices() {
+		availableCountByHintKey[device.TopologyHintKey()] += 1
+	}
+
+	for collectedDevices.Len() < size && candidates.Len() > 0 {
+		var bestDevice Device
+		var lowestScore uint
+
+		for _, candidate := range candidates.Devices() {
+			score := uint(0)
+			for _, collected := range collectedDevices.Devices() {
+				score += s.hintProvider(candidate, collected)
+			}
+
+			if bestDevice == nil || score < lowestScore || (score == lowestScore && s.isLessUsed(candidate, bestDevice, collectedCountByHintKey, availableCountByHintKey)) {
+				bestDevice = candidate
+				lowestScore = score
+			}
+		}
+
+		hintKey := bestDevice.TopologyHintKey()
+		collectedDevices.Insert(bestDevice)
+		collectedCountByHintKey[hintKey] += 1
+		availableCountByHintKey[hintKey] -= 1
+		candidates = candidates.Difference(bestDevice)
+	}
+
+	return collectedDevices
+}
+
+// isLessUsed checks whether the card of device1 is less used than the card of device2.
+// Candidates are visited in index order, so lower index wins if both are equally used.
+func (s *spreadNpuAllocator) isLessUsed(device1, device2 Device, collectedCountByHintKey, availableCountByHintKey map[TopologyHintKey]int) bool {
+	key1, key2 := device1.TopologyHintKey(), device2.TopologyHintKey()
+	if collectedCountByHintKey[key1] != collectedCountByHintKey[key2] {
+		return collectedCountByHintKey[key1] < collectedCountByHintKey[key2]
+	}
+
+	return availableCountByHintKey[key1] > availableCountByHintKey[key2]
+}
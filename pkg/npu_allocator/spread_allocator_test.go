@@ -0,0 +1,124 @@
+package npu_allocator
+
+import (
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpreadNpuAllocator(t *testing.T) {
+	tests := []struct {
+		description string
+		available   DeviceSet
+		required    DeviceSet
+		request     int
+		expected    DeviceSet
+	}{
+		{
+			description: "request two devices, spread across sockets",
+			available:   buildMockDeviceSet(0, 7),
+			required:    NewDeviceSet(),
+			request:     2,
+			expected:    NewDeviceSet(buildMockDevice(0), buildMockDevice(4)),
+		},
+		{
+			description: "request four devices, one device per switch",
+			available:   buildMockDeviceSet(0, 7),
+			required:    NewDeviceSet(),
+			request:     4,
+			expected: NewDeviceSet(
+				buildMockDevice(0),
+				buildMockDevice(2),
+				buildMockDevice(4),
+				buildMockDevice(6),
+			),
+		},
+		{
+			description: "required device is respected",
+			available:   buildMockDeviceSet(0, 7),
+			required:    NewDeviceSet(buildMockDevice(5)),
+			request:     2,
+			expected:    NewDeviceSet(buildMockDevice(0), buildMockDevice(5)),
+		},
+		{
+			description: "required devices satisfy request",
+			available:   buildMockDeviceSet(0, 7),
+			required:    NewDeviceSet(buildMockDevice(0), buildMockDevice(1)),
+			request:     2,
+			expected:    NewDeviceSet(buildMockDevice(0), buildMockDevice(1)),
+		},
+		{
+			description: "only a single socket is available",
+			available:   buildMockDeviceSet(0, 3),
+			required:    NewDeviceSet(),
+			request:     2,
+			expected:    NewDeviceSet(buildMockDevice(0), buildMockDevice(2)),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			sut, _ := NewMockSpreadNpuAllocator(mockTopologyHintProvider(buildStaticHintMatrixForTwoSocketBalancedConfig()))
+			actual := sut.Allocate(tc.available, tc.required, tc.request)
+
+			assert.Truef(t, tc.expected.Equal(actual.Devices()...), "expected %v but got %v", tc.expected.Devices(), actual.Devices())
+		})
+	}
+}
+
+func TestSpreadNpuAllocatorKeepsCardUsageEven(t *testing.T) {
+	sut, _ := NewMockSpreadNpuAllocator(mockTopologyHintProvider(buildStaticHintMatrixForTwoSocketBalancedConfig()))
+
+	// card "0" has only 2 available devices while card "1" has 4.
+	busyCard := generateSameBoardMockDeviceSet(0, 2, "0")
+	idleCard := generateSameBoardMockDeviceSet(1, 4, "1")
+	available := busyCard.Union(idleCard.Devices()...)
+
+	t.Run("less used card is picked first", func(t *testing.T) {
+		actual := sut.Allocate(available, NewDeviceSet(), 1)
+
+		assert.Equal(t, 1, actual.Len())
+		assert.Equal(t, TopologyHintKey("1"), actual.Devices()[0].TopologyHintKey())
+	})
+
+	t.Run("devices are spread evenly across cards", func(t *testing.T) {
+		actual := sut.Allocate(available, NewDeviceSet(), 4)
+
+		hintKeyCntMap := make(map[TopologyHintKey]int)
+		for _, device := range actual.Devices() {
+			hintKeyCntMap[device.TopologyHintKey()] += 1
+		}
+
+		assert.Equal(t, map[TopologyHintKey]int{"0": 2, "1": 2}, hintKeyCntMap)
+	})
+}
+
+func TestSpreadNpuAllocatorWithSmiDevices(t *testing.T) {
+	smiDevices := smi.GetStaticMockDevices(smi.ArchRngd)
+
+	furiosaDevices, err := furiosa_device.NewFuriosaDevices(smiDevices, nil, furiosa_device.NonePolicy)
+	assert.NoError(t, err)
+
+	available := NewDeviceSet()
+	for _, furiosaDevice := range furiosaDevices {
+		available.Insert(NewDevice(furiosaDevice))
+	}
+
+	sut, err := NewSpreadNpuAllocator(smiDevices)
+	assert.NoError(t, err)
+
+	actual := sut.Allocate(available, NewDeviceSet(), 2)
+
+	numaNodes := make(map[int]struct{})
+	for _, device := range actual.Devices() {
+		for _, furiosaDevice := range furiosaDevices {
+			if furiosaDevice.DeviceID() == device.ID() {
+				numaNodes[furiosaDevice.NUMANode()] = struct{}{}
+			}
+		}
+	}
+
+	assert.Len(t, numaNodes, 2)
+}
@@ -0,0 +1,84 @@
+package npu_allocator
+
+import (
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/sysfs"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/util"
+)
+
+// NewTopologyHintMatrixFromSysfs generates TopologyHintMatrix of the given BDFs from PCI hierarchy in sysfs mounted under the root.
+// It doesn't depend on the native library, so it can be used to cross-check TopologyHintMatrix built by NewTopologyHintMatrix.
+// Only LinkTypeWeights and SameNUMANodeBonus of the scoring policy are used, since other factors require smi.Device.
+func NewTopologyHintMatrixFromSysfs(root string, bdfs []string, scoringPolicy TopologyScoringPolicy) (TopologyHintMatrix, error) {
+	pciDevices := make(map[TopologyHintKey]*sysfs.PCIDevice, len(bdfs))
+	for _, bdf := range bdfs {
+		pciDevice, err := sysfs.ReadPCIDevice(root, bdf)
+		if err != nil {
+			return nil, err
+		}
+
+		pciBusID, err := util.ParseBusIDFromBDF(bdf)
+		if err != nil {
+			return nil, err
+		}
+
+		pciDevices[TopologyHintKey(pciBusID)] = pciDevice
+	}
+
+	topologyHintMatrix := make(TopologyHintMatrix)
+	for key1, pciDevice1 := range pciDevices {
+		for key2, pciDevice2 := range pciDevices {
+			if key1 > key2 {
+				continue
+			}
+
+			score := scoringPolicy.LinkTypeWeights.Weight(sysfsLinkType(pciDevice1, pciDevice2))
+			if pciDevice1.NUMANode >= 0 && pciDevice1.NUMANode == pciDevice2.NUMANode {
+				score += scoringPolicy.SameNUMANodeBonus
+			}
+
+			if _, ok := topologyHintMatrix[key1]; !ok {
+				topologyHintMatrix[key1] = make(map[TopologyHintKey]uint)
+			}
+
+			topologyHintMatrix[key1][key2] = score
+		}
+	}
+
+	return topologyHintMatrix, nil
+}
+
+// sysfsLinkType infers smi.LinkType of two PCI devices from their positions in PCI hierarchy.
+//   - LinkTypeNoc: the same device.
+//   - LinkTypeHostBridge: under the same PCIe switch, or behind the same upstream bridge without a switch.
+//   - LinkTypeCpu: attached to the same root complex through different switches or root ports, or to different root complexes of the same NUMA node.
+//   - LinkTypeInterconnect: attached to different NUMA nodes.
+//   - LinkTypeUnknown: NUMA affinity is not reported.
+func sysfsLinkType(device1, device2 *sysfs.PCIDevice) smi.LinkType {
+	if device1.BDF == device2.BDF {
+		return smi.LinkTypeNoc
+	}
+
+	if device1.RootBus == device2.RootBus {
+		if switch1 := device1.Switch(); switch1 != "" && switch1 == device2.Switch() {
+			return smi.LinkTypeHostBridge
+		}
+
+		if device1.Switch() == "" && device2.Switch() == "" &&
+			device1.UpstreamBridge() != "" && device1.UpstreamBridge() == device2.UpstreamBridge() {
+			return smi.LinkTypeHostBridge
+		}
+
+		return smi.LinkTypeCpu
+	}
+
+	if device1.NUMANode < 0 || device2.NUMANode < 0 {
+		return smi.LinkTypeUnknown
+	}
+
+	if device1.NUMANode == device2.NUMANode {
+		return smi.LinkTypeCpu
+	}
+
+	return smi.LinkTypeInterconnect
+}
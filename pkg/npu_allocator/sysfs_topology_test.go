@@ -0,0 +1,106 @@
+package npu_allocator
+
+import (
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/sysfs"
+	"github.com/stretchr/testify/assert"
+)
+
+// buildMockSysfsForTwoSocketBalancedConfig creates a sysfs tree equivalent to smi.GetStaticMockDevices.
+// Each pair of cards shares a PCIe switch under its own root complex, and each NUMA node has two root complexes.
+func buildMockSysfsForTwoSocketBalancedConfig(t *testing.T) (string, []string) {
+	root := t.TempDir()
+	hierarchies := [][]string{
+		{"pci0000:16", "0000:16:01.0", "0000:17:00.0", "0000:18:00.0", "0000:27:00.0"},
+		{"pci0000:16", "0000:16:01.0", "0000:17:00.0", "0000:18:01.0", "0000:2a:00.0"},
+		{"pci0000:40", "0000:40:01.0", "0000:41:00.0", "0000:42:00.0", "0000:51:00.0"},
+		{"pci0000:40", "0000:40:01.0", "0000:41:00.0", "0000:42:01.0", "0000:57:00.0"},
+		{"pci0000:8a", "0000:8a:01.0", "0000:8b:00.0", "0000:8c:00.0", "0000:9e:00.0"},
+		{"pci0000:8a", "0000:8a:01.0", "0000:8b:00.0", "0000:8c:01.0", "0000:a4:00.0"},
+		{"pci0000:b4", "0000:b4:01.0", "0000:b5:00.0", "0000:b6:00.0", "0000:c7:00.0"},
+		{"pci0000:b4", "0000:b4:01.0", "0000:b5:00.0", "0000:b6:01.0", "0000:ca:00.0"},
+	}
+
+	bdfs := make([]string, 0, len(hierarchies))
+	for idx, hierarchy := range hierarchies {
+		numaNode := idx / 4
+		assert.NoError(t, sysfs.CreateMockPCIDevice(root, hierarchy, numaNode, ""))
+		bdfs = append(bdfs, hierarchy[len(hierarchy)-1])
+	}
+
+	return root, bdfs
+}
+
+func TestNewTopologyHintMatrixFromSysfs(t *testing.T) {
+	t.Run("sysfs topology matches smi topology", func(t *testing.T) {
+		root, bdfs := buildMockSysfsForTwoSocketBalancedConfig(t)
+
+		actual, err := NewTopologyHintMatrixFromSysfs(root, bdfs, DefaultTopologyScoringPolicy())
+		assert.NoError(t, err)
+
+		expected, err := NewTopologyHintMatrix(smi.GetStaticMockDevices(smi.ArchRngd))
+		assert.NoError(t, err)
+
+		assert.Equal(t, expected, actual)
+		assert.NoError(t, actual.Validate())
+	})
+
+	t.Run("same numa node bonus and unknown numa node", func(t *testing.T) {
+		root := t.TempDir()
+		assert.NoError(t, sysfs.CreateMockPCIDevice(root, []string{"pci0000:00", "0000:00:01.0", "0000:01:00.0"}, 0, ""))
+		assert.NoError(t, sysfs.CreateMockPCIDevice(root, []string{"pci0000:20", "0000:20:01.0", "0000:21:00.0"}, 0, ""))
+		assert.NoError(t, sysfs.CreateMockPCIDevice(root, []string{"pci0000:40", "0000:40:01.0", "0000:41:00.0"}, -1, ""))
+
+		policy := DefaultTopologyScoringPolicy()
+		policy.SameNUMANodeBonus = 5
+
+		actual, err := NewTopologyHintMatrixFromSysfs(root, []string{"0000:01:00.0", "0000:21:00.0", "0000:41:00.0"}, policy)
+		assert.NoError(t, err)
+		assert.Equal(t, TopologyHintMatrix{
+			"01": {"01": 75, "21": 25, "41": 0},
+			"21": {"21": 75, "41": 0},
+			"41": {"41": 70},
+		}, actual)
+	})
+
+	t.Run("shared switch ranks above shared root complex only", func(t *testing.T) {
+		root := t.TempDir()
+		// 01 and 02 share a PCIe switch, while 03 is under another root port of the same root complex.
+		assert.NoError(t, sysfs.CreateMockPCIDevice(root, []string{"pci0000:00", "0000:00:01.0", "0000:10:00.0", "0000:11:00.0", "0000:01:00.0"}, 0, ""))
+		assert.NoError(t, sysfs.CreateMockPCIDevice(root, []string{"pci0000:00", "0000:00:01.0", "0000:10:00.0", "0000:11:01.0", "0000:02:00.0"}, 0, ""))
+		assert.NoError(t, sysfs.CreateMockPCIDevice(root, []string{"pci0000:00", "0000:00:02.0", "0000:20:00.0", "0000:21:00.0", "0000:03:00.0"}, 0, ""))
+
+		actual, err := NewTopologyHintMatrixFromSysfs(root, []string{"0000:01:00.0", "0000:02:00.0", "0000:03:00.0"}, DefaultTopologyScoringPolicy())
+		assert.NoError(t, err)
+		assert.Equal(t, TopologyHintMatrix{
+			"01": {"01": 70, "02": 30, "03": 20},
+			"02": {"02": 70, "03": 20},
+			"03": {"03": 70},
+		}, actual)
+	})
+
+	t.Run("same upstream bridge without switch", func(t *testing.T) {
+		root := t.TempDir()
+		// functions of a device under the same root port, and a device under another root port of the same root complex.
+		assert.NoError(t, sysfs.CreateMockPCIDevice(root, []string{"pci0000:00", "0000:00:01.0", "0000:01:00.0"}, 0, ""))
+		assert.NoError(t, sysfs.CreateMockPCIDevice(root, []string{"pci0000:00", "0000:00:01.0", "0000:01:00.1"}, 0, ""))
+		assert.NoError(t, sysfs.CreateMockPCIDevice(root, []string{"pci0000:00", "0000:00:02.0", "0000:02:00.0"}, 0, ""))
+
+		pciDevices := make([]*sysfs.PCIDevice, 0, 3)
+		for _, bdf := range []string{"0000:01:00.0", "0000:01:00.1", "0000:02:00.0"} {
+			pciDevice, err := sysfs.ReadPCIDevice(root, bdf)
+			assert.NoError(t, err)
+			pciDevices = append(pciDevices, pciDevice)
+		}
+
+		assert.Equal(t, smi.LinkTypeHostBridge, sysfsLinkType(pciDevices[0], pciDevices[1]))
+		assert.Equal(t, smi.LinkTypeCpu, sysfsLinkType(pciDevices[0], pciDevices[2]))
+	})
+
+	t.Run("missing device", func(t *testing.T) {
+		_, err := NewTopologyHintMatrixFromSysfs(t.TempDir(), []string{"0000:27:00.0"}, DefaultTopologyScoringPolicy())
+		assert.Error(t, err)
+	})
+}
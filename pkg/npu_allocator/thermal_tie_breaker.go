@@ -0,0 +1,126 @@
+package npu_allocator
+
+import (
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/util"
+)
+
+// ThermalPolicy describes how live telemetry of a card is converted into a penalty. A card with higher penalty is less preferred.
+type ThermalPolicy struct {
+	// TemperatureWeight is multiplied by SoC peak temperature in Celsius.
+	TemperatureWeight float64 `json:"temperatureWeight"`
+
+	// PowerWeight is multiplied by power consumption in Watt.
+	PowerWeight float64 `json:"powerWeight"`
+
+	// ThrottlePenalty is added if the card is throttled for a reason other than idle.
+	ThrottlePenalty float64 `json:"throttlePenalty"`
+}
+
+// DefaultThermalPolicy returns ThermalPolicy where throttling outweighs temperature, and temperature outweighs power.
+func DefaultThermalPolicy() ThermalPolicy {
+	return ThermalPolicy{
+		TemperatureWeight: 1,
+		PowerWeight:       0.1,
+		ThrottlePenalty:   1000,
+	}
+}
+
+// WithThermalTieBreaker breaks ties of topology scores using live telemetry of smi.Device,
+// so that hot, power-hungry or throttled cards receive fewer new workloads.
+func WithThermalTieBreaker(smiDevices []smi.Device, policy ThermalPolicy) AllocatorOption {
+	return func(o *allocatorOptions) {
+		o.thermalDevices = smiDevices
+		o.thermalPolicy = policy
+	}
+}
+
+// thermalPenaltyCalculator returns the sum of thermal penalties of the given keys, counting each key once.
+type thermalPenaltyCalculator func(keys []TopologyHintKey) float64
+
+// thermalTieBreaker reads telemetry of cards, keyed by TopologyHintKey.
+type thermalTieBreaker struct {
+	devices map[TopologyHintKey]smi.Device
+	policy  ThermalPolicy
+}
+
+// newThermalTieBreaker returns nil if the option is not given.
+func newThermalTieBreaker(options *allocatorOptions) (*thermalTieBreaker, error) {
+	if options.thermalDevices == nil {
+		return nil, nil
+	}
+
+	devices := make(map[TopologyHintKey]smi.Device, len(options.thermalDevices))
+	for _, device := range options.thermalDevices {
+		deviceInfo, err := device.DeviceInfo()
+		if err != nil {
+			return nil, err
+		}
+
+		pciBusID, err := util.ParseBusIDFromBDF(deviceInfo.BDF())
+		if err != nil {
+			return nil, err
+		}
+
+		devices[TopologyHintKey(pciBusID)] = device
+	}
+
+	return &thermalTieBreaker{devices: devices, policy: options.thermalPolicy}, nil
+}
+
+// penalty calculates the penalty of a card. Telemetry that couldn't be read doesn't contribute to the penalty.
+func (t *thermalTieBreaker) penalty(key TopologyHintKey) float64 {
+	device, ok := t.devices[key]
+	if !ok {
+		return 0
+	}
+
+	penalty := 0.0
+	if temperature, err := device.DeviceTemperature(); err == nil {
+		penalty += t.policy.TemperatureWeight * temperature.SocPeak()
+	}
+
+	if power, err := device.PowerConsumption(); err == nil {
+		penalty += t.policy.PowerWeight * power
+	}
+
+	if reason, err := device.ThrottleReason(); err == nil && reason != smi.ThrottleReasonNone && reason != smi.ThrottleReasonIdle {
+		penalty += t.policy.ThrottlePenalty
+	}
+
+	return penalty
+}
+
+// snapshot returns thermalPenaltyCalculator reading telemetry of each card at most once.
+// It is expected to be called once per allocation, and always returns zero for nil thermalTieBreaker.
+func (t *thermalTieBreaker) snapshot() thermalPenaltyCalculator {
+	if t == nil {
+		return func(_ []TopologyHintKey) float64 {
+			return 0
+		}
+	}
+
+	penalties := make(map[TopologyHintKey]float64)
+
+	return func(keys []TopologyHintKey) float64 {
+		seen := make(map[TopologyHintKey]struct{}, len(keys))
+		total := 0.0
+		for _, key := range keys {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			seen[key] = struct{}{}
+
+			penalty, ok := penalties[key]
+			if !ok {
+				penalty = t.penalty(key)
+				penalties[key] = penalty
+			}
+
+			total += penalty
+		}
+
+		return total
+	}
+}
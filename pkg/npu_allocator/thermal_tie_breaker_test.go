@@ -0,0 +1,93 @@
+package npu_allocator
+
+import (
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/stretchr/testify/assert"
+)
+
+// thermalOverriddenDevice overrides temperature and throttle reason of the embedded smi.Device.
+type thermalOverriddenDevice struct {
+	smi.Device
+	socPeak        float64
+	throttleReason smi.ThrottleReason
+}
+
+func (d *thermalOverriddenDevice) DeviceTemperature() (smi.DeviceTemperature, error) {
+	return &mockDeviceTemperature{socPeak: d.socPeak}, nil
+}
+
+func (d *thermalOverriddenDevice) ThrottleReason() (smi.ThrottleReason, error) {
+	return d.throttleReason, nil
+}
+
+func TestThermalTieBreaker(t *testing.T) {
+	origins := smi.GetStaticMockDevices(smi.ArchRngd)
+	hintKeys := []TopologyHintKey{"27", "2a", "51", "57", "9e", "a4", "c7", "ca"}
+
+	topologyHintMatrix, err := NewTopologyHintMatrix(origins)
+	assert.NoError(t, err)
+
+	available := NewDeviceSet()
+	for idx, hintKey := range hintKeys {
+		available.Insert(NewMockDevice(idx, string(hintKey), hintKey))
+	}
+
+	buildThermalDevices := func(socPeaks []float64, throttled int) []smi.Device {
+		devices := make([]smi.Device, 0, len(origins))
+		for idx, origin := range origins {
+			device := &thermalOverriddenDevice{Device: origin, socPeak: socPeaks[idx], throttleReason: smi.ThrottleReasonNone}
+			if idx == throttled {
+				device.throttleReason = smi.ThrottleReasonThermalSlowdown
+			}
+
+			devices = append(devices, device)
+		}
+
+		return devices
+	}
+
+	tests := []struct {
+		description string
+		opts        []AllocatorOption
+		expected    []TopologyHintKey
+	}{
+		{
+			description: "without tie breaker, the first set is taken",
+			opts:        nil,
+			expected:    []TopologyHintKey{"27", "2a"},
+		},
+		{
+			description: "hot card is avoided",
+			opts: []AllocatorOption{WithThermalTieBreaker(
+				buildThermalDevices([]float64{90, 40, 60, 60, 50, 50, 55, 55}, -1),
+				DefaultThermalPolicy(),
+			)},
+			expected: []TopologyHintKey{"9e", "a4"},
+		},
+		{
+			description: "throttled card is avoided",
+			opts: []AllocatorOption{WithThermalTieBreaker(
+				buildThermalDevices([]float64{40, 40, 50, 50, 50, 50, 50, 50}, 1),
+				DefaultThermalPolicy(),
+			)},
+			expected: []TopologyHintKey{"51", "57"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			binPacking, err := NewMockBinPackingNpuAllocator(topologyHintMatrix, tc.opts...)
+			assert.NoError(t, err)
+
+			scoreBased, err := NewMockScoreBasedOptimalNpuAllocator(generateTopologyHintProvider(topologyHintMatrix), tc.opts...)
+			assert.NoError(t, err)
+
+			for _, sut := range []NpuAllocator{binPacking, scoreBased} {
+				actual := sut.Allocate(available, NewDeviceSet(), 2)
+				assert.Equal(t, tc.expected, deviceSetHintKeys(actual))
+			}
+		})
+	}
+}
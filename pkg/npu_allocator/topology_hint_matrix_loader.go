@@ -0,0 +1,144 @@
+package npu_allocator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Validate checks whether TopologyHintMatrix is well-formed.
+//   - every key must have its diagonal score.
+//   - every inner key must be a known key, which means it also has its own row.
+//   - scores must be stored only once per pair, with the smaller key as the outer key.
+func (m TopologyHintMatrix) Validate() error {
+	for key1, innerMap := range m {
+		if _, ok := innerMap[key1]; !ok {
+			return fmt.Errorf("diagonal score of key %q is missing", key1)
+		}
+
+		for key2 := range innerMap {
+			if _, ok := m[key2]; !ok {
+				return fmt.Errorf("key %q referenced by key %q is unknown", key2, key1)
+			}
+
+			if key1 > key2 {
+				return fmt.Errorf("score of (%q, %q) must be stored under key %q", key1, key2, key2)
+			}
+		}
+	}
+
+	return nil
+}
+
+// normalizeTopologyHintMatrix moves scores stored with the larger key as the outer key to the smaller one.
+// If both (key1, key2) and (key2, key1) exist, they must have the same score.
+func normalizeTopologyHintMatrix(raw TopologyHintMatrix) (TopologyHintMatrix, error) {
+	normalized := make(TopologyHintMatrix, len(raw))
+	for key := range raw {
+		normalized[key] = make(map[TopologyHintKey]uint)
+	}
+
+	for key1, innerMap := range raw {
+		for key2, score := range innerMap {
+			smaller, larger := key1, key2
+			if smaller > larger {
+				smaller, larger = larger, smaller
+			}
+
+			if _, ok := normalized[smaller]; !ok {
+				return nil, fmt.Errorf("key %q referenced by key %q is unknown", smaller, larger)
+			}
+
+			if existing, ok := normalized[smaller][larger]; ok && existing != score {
+				return nil, fmt.Errorf("score of (%q, %q) is not symmetric: %d and %d", key1, key2, existing, score)
+			}
+
+			normalized[smaller][larger] = score
+		}
+	}
+
+	return normalized, nil
+}
+
+// NewTopologyHintMatrixFromJSON parses and validates TopologyHintMatrix from JSON.
+// Scores may be given in either or both directions of a pair, such as {"0": {"1": 30}} or {"1": {"0": 30}}.
+func NewTopologyHintMatrixFromJSON(data []byte) (TopologyHintMatrix, error) {
+	var raw TopologyHintMatrix
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("couldn't parse topology hint matrix: %w", err)
+	}
+
+	normalized, err := normalizeTopologyHintMatrix(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = normalized.Validate(); err != nil {
+		return nil, err
+	}
+
+	return normalized, nil
+}
+
+// NewTopologyHintMatrixFromYAML parses and validates TopologyHintMatrix from YAML.
+func NewTopologyHintMatrixFromYAML(data []byte) (TopologyHintMatrix, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse topology hint matrix: %w", err)
+	}
+
+	return NewTopologyHintMatrixFromJSON(jsonData)
+}
+
+// LoadTopologyHintMatrix reads TopologyHintMatrix from the file. YAML is used for ".yaml" and ".yml" extensions, JSON otherwise.
+func LoadTopologyHintMatrix(path string) (TopologyHintMatrix, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return NewTopologyHintMatrixFromYAML(data)
+
+	default:
+		return NewTopologyHintMatrixFromJSON(data)
+	}
+}
+
+// ToJSON serializes TopologyHintMatrix to JSON after validation.
+func (m TopologyHintMatrix) ToJSON() ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// ToYAML serializes TopologyHintMatrix to YAML after validation.
+func (m TopologyHintMatrix) ToYAML() ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(m)
+}
+
+// Keys returns sorted keys of TopologyHintMatrix.
+func (m TopologyHintMatrix) Keys() []TopologyHintKey {
+	keys := make([]TopologyHintKey, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+
+	return keys
+}
@@ -0,0 +1,137 @@
+package npu_allocator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTopologyHintMatrixFromJSON(t *testing.T) {
+	tests := []struct {
+		description string
+		input       string
+		expected    TopologyHintMatrix
+		expectError bool
+	}{
+		{
+			description: "upper triangular matrix",
+			input:       `{"0": {"0": 70, "1": 30}, "1": {"1": 70}}`,
+			expected:    TopologyHintMatrix{"0": {"0": 70, "1": 30}, "1": {"1": 70}},
+		},
+		{
+			description: "lower triangular matrix is normalized",
+			input:       `{"0": {"0": 70}, "1": {"0": 30, "1": 70}}`,
+			expected:    TopologyHintMatrix{"0": {"0": 70, "1": 30}, "1": {"1": 70}},
+		},
+		{
+			description: "full matrix with symmetric scores",
+			input:       `{"0": {"0": 70, "1": 30}, "1": {"0": 30, "1": 70}}`,
+			expected:    TopologyHintMatrix{"0": {"0": 70, "1": 30}, "1": {"1": 70}},
+		},
+		{
+			description: "asymmetric scores",
+			input:       `{"0": {"0": 70, "1": 30}, "1": {"0": 20, "1": 70}}`,
+			expectError: true,
+		},
+		{
+			description: "missing diagonal",
+			input:       `{"0": {"0": 70, "1": 30}, "1": {}}`,
+			expectError: true,
+		},
+		{
+			description: "unknown key",
+			input:       `{"0": {"0": 70, "2": 30}, "1": {"1": 70}}`,
+			expectError: true,
+		},
+		{
+			description: "negative score",
+			input:       `{"0": {"0": -70}}`,
+			expectError: true,
+		},
+		{
+			description: "malformed json",
+			input:       `{"0": `,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			actual, err := NewTopologyHintMatrixFromJSON([]byte(tc.input))
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestTopologyHintMatrixRoundTrip(t *testing.T) {
+	liveMatrix, err := NewTopologyHintMatrix(smi.GetStaticMockDevices(smi.ArchRngd))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		description string
+		matrix      TopologyHintMatrix
+	}{
+		{
+			description: "static two socket balanced config",
+			matrix:      buildStaticHintMatrixForTwoSocketBalancedConfig(),
+		},
+		{
+			description: "matrix captured from smi devices",
+			matrix:      liveMatrix,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			dir := t.TempDir()
+
+			jsonData, err := tc.matrix.ToJSON()
+			assert.NoError(t, err)
+			jsonPath := filepath.Join(dir, "topology.json")
+			assert.NoError(t, os.WriteFile(jsonPath, jsonData, 0644))
+
+			yamlData, err := tc.matrix.ToYAML()
+			assert.NoError(t, err)
+			yamlPath := filepath.Join(dir, "topology.yaml")
+			assert.NoError(t, os.WriteFile(yamlPath, yamlData, 0644))
+
+			for _, path := range []string{jsonPath, yamlPath} {
+				actual, err := LoadTopologyHintMatrix(path)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.matrix, actual)
+			}
+		})
+	}
+
+	t.Run("invalid matrix is not serialized", func(t *testing.T) {
+		_, err := TopologyHintMatrix{"0": {"1": 30}}.ToJSON()
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadedTopologyHintMatrixReproducesAllocation(t *testing.T) {
+	data, err := buildStaticHintMatrixForTwoSocketBalancedConfig().ToYAML()
+	assert.NoError(t, err)
+
+	loaded, err := NewTopologyHintMatrixFromYAML(data)
+	assert.NoError(t, err)
+
+	expectedAllocator, _ := NewMockBinPackingNpuAllocator(buildStaticHintMatrixForTwoSocketBalancedConfig())
+	sut, _ := NewMockBinPackingNpuAllocator(loaded)
+
+	for request := 1; request <= 8; request++ {
+		expected := expectedAllocator.Allocate(buildMockDeviceSet(0, 7), NewDeviceSet(), request)
+		actual := sut.Allocate(buildMockDeviceSet(0, 7), NewDeviceSet(), request)
+
+		assert.True(t, expected.Equal(actual.Devices()...))
+	}
+}
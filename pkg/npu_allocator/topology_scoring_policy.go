@@ -0,0 +1,102 @@
+package npu_allocator
+
+import (
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+)
+
+// LinkTypeWeights is a score table of smi.LinkType used to build TopologyHintMatrix.
+type LinkTypeWeights struct {
+	Noc          uint `json:"noc"`
+	HostBridge   uint `json:"hostBridge"`
+	Cpu          uint `json:"cpu"`
+	Interconnect uint `json:"interconnect"`
+	Unknown      uint `json:"unknown"`
+}
+
+// Weight returns the score of the given smi.LinkType.
+func (w LinkTypeWeights) Weight(linkType smi.LinkType) uint {
+	switch linkType {
+	case smi.LinkTypeNoc:
+		return w.Noc
+
+	case smi.LinkTypeHostBridge:
+		return w.HostBridge
+
+	case smi.LinkTypeCpu:
+		return w.Cpu
+
+	case smi.LinkTypeInterconnect:
+		return w.Interconnect
+
+	default:
+		return w.Unknown
+	}
+}
+
+// TopologyScoreFactor returns an extra score of two devices, added on top of the link type weight.
+type TopologyScoreFactor func(device1, device2 smi.Device) (uint, error)
+
+// TopologyScoringPolicy describes how a score of two devices is calculated in TopologyHintMatrix.
+// It allows tuning allocation preferences per server SKU.
+type TopologyScoringPolicy struct {
+	LinkTypeWeights LinkTypeWeights `json:"linkTypeWeights"`
+
+	// P2PAccessibleBonus is added if two devices are p2p accessible each other.
+	P2PAccessibleBonus uint `json:"p2pAccessibleBonus"`
+
+	// SameNUMANodeBonus is added if two devices are attached to the same NUMA node.
+	SameNUMANodeBonus uint `json:"sameNumaNodeBonus"`
+
+	// ExtraFactors are evaluated once for every unordered pair of devices, and their scores are added.
+	// device1 is always the device with the smaller TopologyHintKey.
+	ExtraFactors []TopologyScoreFactor `json:"-"`
+}
+
+// DefaultTopologyScoringPolicy returns TopologyScoringPolicy using values of smi.LinkType as weights without any bonus.
+func DefaultTopologyScoringPolicy() TopologyScoringPolicy {
+	return TopologyScoringPolicy{
+		LinkTypeWeights: LinkTypeWeights{
+			Noc:          uint(smi.LinkTypeNoc),
+			HostBridge:   uint(smi.LinkTypeHostBridge),
+			Cpu:          uint(smi.LinkTypeCpu),
+			Interconnect: uint(smi.LinkTypeInterconnect),
+			Unknown:      uint(smi.LinkTypeUnknown),
+		},
+	}
+}
+
+// score calculates the score of two devices.
+func (p TopologyScoringPolicy) score(device1 smi.Device, deviceInfo1 smi.DeviceInfo, device2 smi.Device, deviceInfo2 smi.DeviceInfo) (uint, error) {
+	linkType, err := device1.DeviceToDeviceLinkType(device2)
+	if err != nil {
+		return 0, err
+	}
+
+	score := p.LinkTypeWeights.Weight(linkType)
+
+	if p.P2PAccessibleBonus > 0 {
+		p2pAccessible, err := device1.P2PAccessible(device2)
+		if err != nil {
+			return 0, err
+		}
+
+		if p2pAccessible {
+			score += p.P2PAccessibleBonus
+		}
+	}
+
+	if p.SameNUMANodeBonus > 0 && deviceInfo1.NumaNode() == deviceInfo2.NumaNode() {
+		score += p.SameNUMANodeBonus
+	}
+
+	for _, factor := range p.ExtraFactors {
+		extraScore, err := factor(device1, device2)
+		if err != nil {
+			return 0, err
+		}
+
+		score += extraScore
+	}
+
+	return score, nil
+}
@@ -0,0 +1,204 @@
+package npu_allocator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTopologyHintMatrixWithScoringPolicy(t *testing.T) {
+	smiDevices := smi.GetStaticMockDevices(smi.ArchRngd)
+
+	customWeights := LinkTypeWeights{Noc: 100, HostBridge: 50, Cpu: 5, Interconnect: 1, Unknown: 0}
+
+	tests := []struct {
+		description   string
+		scoringPolicy TopologyScoringPolicy
+		expected      TopologyHintMatrix
+		expectError   bool
+	}{
+		{
+			description:   "default policy uses value of link type",
+			scoringPolicy: DefaultTopologyScoringPolicy(),
+			expected: TopologyHintMatrix{
+				"27": {"27": 70, "2a": 30, "51": 20, "57": 20, "9e": 10, "a4": 10, "c7": 10, "ca": 10},
+				"2a": {"2a": 70, "51": 20, "57": 20, "9e": 10, "a4": 10, "c7": 10, "ca": 10},
+				"51": {"51": 70, "57": 30, "9e": 10, "a4": 10, "c7": 10, "ca": 10},
+				"57": {"57": 70, "9e": 10, "a4": 10, "c7": 10, "ca": 10},
+				"9e": {"9e": 70, "a4": 30, "c7": 20, "ca": 20},
+				"a4": {"a4": 70, "c7": 20, "ca": 20},
+				"c7": {"c7": 70, "ca": 30},
+				"ca": {"ca": 70},
+			},
+		},
+		{
+			description:   "custom link type weights",
+			scoringPolicy: TopologyScoringPolicy{LinkTypeWeights: customWeights},
+			expected: TopologyHintMatrix{
+				"27": {"27": 100, "2a": 50, "51": 5, "57": 5, "9e": 1, "a4": 1, "c7": 1, "ca": 1},
+				"2a": {"2a": 100, "51": 5, "57": 5, "9e": 1, "a4": 1, "c7": 1, "ca": 1},
+				"51": {"51": 100, "57": 50, "9e": 1, "a4": 1, "c7": 1, "ca": 1},
+				"57": {"57": 100, "9e": 1, "a4": 1, "c7": 1, "ca": 1},
+				"9e": {"9e": 100, "a4": 50, "c7": 5, "ca": 5},
+				"a4": {"a4": 100, "c7": 5, "ca": 5},
+				"c7": {"c7": 100, "ca": 50},
+				"ca": {"ca": 100},
+			},
+		},
+		{
+			description: "same NUMA node bonus and p2p accessible bonus",
+			scoringPolicy: TopologyScoringPolicy{
+				LinkTypeWeights:    customWeights,
+				P2PAccessibleBonus: 2,
+				SameNUMANodeBonus:  1000,
+			},
+			expected: TopologyHintMatrix{
+				"27": {"27": 1102, "2a": 1052, "51": 1007, "57": 1007, "9e": 3, "a4": 3, "c7": 3, "ca": 3},
+				"2a": {"2a": 1102, "51": 1007, "57": 1007, "9e": 3, "a4": 3, "c7": 3, "ca": 3},
+				"51": {"51": 1102, "57": 1052, "9e": 3, "a4": 3, "c7": 3, "ca": 3},
+				"57": {"57": 1102, "9e": 3, "a4": 3, "c7": 3, "ca": 3},
+				"9e": {"9e": 1102, "a4": 1052, "c7": 1007, "ca": 1007},
+				"a4": {"a4": 1102, "c7": 1007, "ca": 1007},
+				"c7": {"c7": 1102, "ca": 1052},
+				"ca": {"ca": 1102},
+			},
+		},
+		{
+			description: "extra factor",
+			scoringPolicy: TopologyScoringPolicy{
+				LinkTypeWeights: customWeights,
+				ExtraFactors: []TopologyScoreFactor{
+					func(device1, device2 smi.Device) (uint, error) {
+						return 1000, nil
+					},
+				},
+			},
+			expected: TopologyHintMatrix{
+				"27": {"27": 1100, "2a": 1050, "51": 1005, "57": 1005, "9e": 1001, "a4": 1001, "c7": 1001, "ca": 1001},
+				"2a": {"2a": 1100, "51": 1005, "57": 1005, "9e": 1001, "a4": 1001, "c7": 1001, "ca": 1001},
+				"51": {"51": 1100, "57": 1050, "9e": 1001, "a4": 1001, "c7": 1001, "ca": 1001},
+				"57": {"57": 1100, "9e": 1001, "a4": 1001, "c7": 1001, "ca": 1001},
+				"9e": {"9e": 1100, "a4": 1050, "c7": 1005, "ca": 1005},
+				"a4": {"a4": 1100, "c7": 1005, "ca": 1005},
+				"c7": {"c7": 1100, "ca": 1050},
+				"ca": {"ca": 1100},
+			},
+		},
+		{
+			description: "error from extra factor",
+			scoringPolicy: TopologyScoringPolicy{
+				ExtraFactors: []TopologyScoreFactor{
+					func(device1, device2 smi.Device) (uint, error) {
+						return 0, fmt.Errorf("telemetry is not available")
+					},
+				},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			actual, err := NewTopologyHintMatrixWithScoringPolicy(smiDevices, tc.scoringPolicy)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestNewTopologyHintMatrixWithAsymmetricExtraFactor(t *testing.T) {
+	smiDevices := smi.GetStaticMockDevices(smi.ArchRngd)
+
+	// the factor depends on the order of devices, and each pair must be evaluated with the smaller key first.
+	scoringPolicy := TopologyScoringPolicy{
+		ExtraFactors: []TopologyScoreFactor{
+			func(device1, device2 smi.Device) (uint, error) {
+				deviceInfo1, err := device1.DeviceInfo()
+				if err != nil {
+					return 0, err
+				}
+
+				deviceInfo2, err := device2.DeviceInfo()
+				if err != nil {
+					return 0, err
+				}
+
+				if deviceInfo1.BDF() < deviceInfo2.BDF() {
+					return 1, nil
+				}
+
+				return 0, nil
+			},
+		},
+	}
+
+	expected, err := NewTopologyHintMatrixWithScoringPolicy(smiDevices, scoringPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), expected["27"]["ca"])
+	assert.Equal(t, uint(0), expected["27"]["27"])
+
+	for i := 0; i < 20; i++ {
+		actual, err := NewTopologyHintMatrixWithScoringPolicy(smiDevices, scoringPolicy)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestAllocatorWithTopologyScoringPolicy(t *testing.T) {
+	smiDevices := smi.GetStaticMockDevices(smi.ArchRngd)
+
+	furiosaDevices, err := furiosa_device.NewFuriosaDevices(smiDevices, nil, furiosa_device.NonePolicy)
+	assert.NoError(t, err)
+
+	devices := make([]Device, 0, len(furiosaDevices))
+	for _, furiosaDevice := range furiosaDevices {
+		devices = append(devices, NewDevice(furiosaDevice))
+	}
+
+	// a SKU preferring cross socket links over links under the same socket.
+	crossSocketPreferredPolicy := TopologyScoringPolicy{
+		LinkTypeWeights: LinkTypeWeights{Noc: 70, HostBridge: 30, Cpu: 10, Interconnect: 20},
+	}
+
+	available := NewDeviceSet(devices[0], devices[2], devices[4])
+
+	tests := []struct {
+		description string
+		opts        []AllocatorOption
+		expected    DeviceSet
+	}{
+		{
+			description: "default policy",
+			opts:        nil,
+			expected:    NewDeviceSet(devices[0], devices[2]),
+		},
+		{
+			description: "cross socket preferred policy",
+			opts:        []AllocatorOption{WithTopologyScoringPolicy(crossSocketPreferredPolicy)},
+			expected:    NewDeviceSet(devices[0], devices[4]),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			binPackingAllocator, err := NewBinPackingNpuAllocator(smiDevices, tc.opts...)
+			assert.NoError(t, err)
+
+			scoreBasedAllocator, err := NewScoreBasedOptimalNpuAllocator(smiDevices, tc.opts...)
+			assert.NoError(t, err)
+
+			for _, sut := range []NpuAllocator{binPackingAllocator, scoreBasedAllocator} {
+				actual := sut.Allocate(available, NewDeviceSet(), 2)
+				assert.Truef(t, tc.expected.Equal(actual.Devices()...), "expected %v but got %v", tc.expected.Devices(), actual.Devices())
+			}
+		})
+	}
+}
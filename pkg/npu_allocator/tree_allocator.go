@@ -0,0 +1,276 @@
+package npu_allocator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/sysfs"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/util"
+)
+
+var _ NpuAllocator = (*treeNpuAllocator)(nil)
+
+// TopologyTreePaths describes the topology tree of cards.
+// Each value is a list of ancestors of the card from the top, such as NUMA node, host bridge and switch, excluding the machine itself.
+// Every card must have the same number of ancestors.
+type TopologyTreePaths map[TopologyHintKey][]string
+
+// treeNpuAllocator models the topology as a tree (machine -> NUMA node -> host bridge -> switch -> card -> partition),
+// and allocates devices from the lowest subtree that fits the request.
+type treeNpuAllocator struct {
+	paths TopologyTreePaths
+	depth int
+}
+
+// NewTreeNpuAllocator builds the topology tree from smi.Device.
+// Cards are grouped by NUMA node and host bridge, since smi.LinkType doesn't distinguish switches under the same host bridge.
+// The tree is built from link types only, so it takes no AllocatorOption and TopologyScoringPolicy doesn't apply.
+func NewTreeNpuAllocator(devices []smi.Device) (NpuAllocator, error) {
+	checker, err := newTopologyConstraintChecker(devices)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]TopologyHintKey, 0, len(checker.numaNodes))
+	for key := range checker.numaNodes {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+
+	// Cards connected with smi.LinkTypeHostBridge or better are under the same host bridge, named after the smallest key.
+	hostBridges := make(map[TopologyHintKey]TopologyHintKey)
+	for _, key := range keys {
+		if _, ok := hostBridges[key]; ok {
+			continue
+		}
+
+		hostBridges[key] = key
+		for _, other := range keys {
+			if _, ok := hostBridges[other]; !ok && checker.linkTypes[key][other] >= smi.LinkTypeHostBridge {
+				hostBridges[other] = key
+			}
+		}
+	}
+
+	paths := make(TopologyTreePaths, len(keys))
+	for _, key := range keys {
+		paths[key] = []string{fmt.Sprintf("numa-%d", checker.numaNodes[key]), fmt.Sprintf("hostbridge-%s", hostBridges[key])}
+	}
+
+	return newTreeNpuAllocator(paths)
+}
+
+// NewTreeNpuAllocatorFromSysfs builds the topology tree of the given BDFs from PCI hierarchy in sysfs mounted under the root.
+func NewTreeNpuAllocatorFromSysfs(root string, bdfs []string) (NpuAllocator, error) {
+	paths := make(TopologyTreePaths, len(bdfs))
+	for _, bdf := range bdfs {
+		pciDevice, err := sysfs.ReadPCIDevice(root, bdf)
+		if err != nil {
+			return nil, err
+		}
+
+		pciBusID, err := util.ParseBusIDFromBDF(bdf)
+		if err != nil {
+			return nil, err
+		}
+
+		// A card attached to the root port directly forms its own switch level.
+		switchID := pciDevice.Switch()
+		if switchID == "" {
+			switchID = pciDevice.UpstreamBridge()
+		}
+
+		if switchID == "" {
+			switchID = bdf
+		}
+
+		paths[TopologyHintKey(pciBusID)] = []string{fmt.Sprintf("numa-%d", pciDevice.NUMANode), pciDevice.RootBus, switchID}
+	}
+
+	return newTreeNpuAllocator(paths)
+}
+
+func NewMockTreeNpuAllocator(paths TopologyTreePaths) (NpuAllocator, error) {
+	return newTreeNpuAllocator(paths)
+}
+
+func newTreeNpuAllocator(paths TopologyTreePaths) (NpuAllocator, error) {
+	depth := -1
+	for key, path := range paths {
+		if depth == -1 {
+			depth = len(path)
+		}
+
+		if len(path) != depth {
+			return nil, fmt.Errorf("topology tree path of key %q has %d ancestors, expected %d", key, len(path), depth)
+		}
+	}
+
+	if depth == -1 {
+		depth = 0
+	}
+
+	return &treeNpuAllocator{
+		paths: paths,
+		depth: depth,
+	}, nil
+}
+
+// fullPath returns ancestors of the key followed by the key itself.
+// An unknown key is treated as a separate subtree at every level below the machine.
+func (t *treeNpuAllocator) fullPath(key TopologyHintKey) []string {
+	ancestors, ok := t.paths[key]
+	if !ok {
+		ancestors = make([]string, t.depth)
+		for i := range ancestors {
+			ancestors[i] = "unknown-" + string(key)
+		}
+	}
+
+	return append(append(make([]string, 0, t.depth+1), ancestors...), string(key))
+}
+
+// subtreeID returns an identifier of the subtree containing the key at the given level. Level 0 is the machine.
+func (t *treeNpuAllocator) subtreeID(key TopologyHintKey, level int) string {
+	return strings.Join(t.fullPath(key)[:level], "/")
+}
+
+// commonLevel returns the level of the lowest subtree containing both keys.
+func (t *treeNpuAllocator) commonLevel(key1, key2 TopologyHintKey) int {
+	path1, path2 := t.fullPath(key1), t.fullPath(key2)
+	level := 0
+	for level < len(path1) && path1[level] == path2[level] {
+		level++
+	}
+
+	return level
+}
+
+// Allocate finds the lowest subtree containing all required devices and at least `size` devices.
+// If several subtrees at the same level fit, the one with the fewest devices is chosen to keep larger subtrees intact.
+// It returns empty DeviceSet if the request cannot be satisfied.
+func (t *treeNpuAllocator) Allocate(available DeviceSet, required DeviceSet, size int) DeviceSet {
+	// If length of `required` already satisfies given `size`, just return it.
+	if required.Len() == size {
+		return required
+	}
+
+	candidates := available.Union(required.Devices()...)
+	if required.Len() > size || candidates.Len() < size {
+		return NewDeviceSet()
+	}
+
+	devicesByHintKey := make(map[TopologyHintKey][]Device)
+	for _, device := range candidates.Devices() {
+		hintKey := device.TopologyHintKey()
+		devicesByHintKey[hintKey] = append(devicesByHintKey[hintKey], device)
+	}
+
+	hintKeys := uniqueHintKeys(candidates.Devices())
+	requiredHintKeys := uniqueHintKeys(required.Devices())
+
+	for level := t.depth + 1; level >= 0; level-- {
+		keysBySubtree := make(map[string][]TopologyHintKey)
+		subtreeIDs := make([]string, 0)
+		for _, key := range hintKeys {
+			id := t.subtreeID(key, level)
+			if _, ok := keysBySubtree[id]; !ok {
+				subtreeIDs = append(subtreeIDs, id)
+			}
+
+			keysBySubtree[id] = append(keysBySubtree[id], key)
+		}
+
+		var bestKeys []TopologyHintKey
+		bestCount := 0
+		for _, id := range subtreeIDs {
+			keys := keysBySubtree[id]
+			if !containsAllHintKeys(keys, requiredHintKeys) {
+				continue
+			}
+
+			count := 0
+			for _, key := range keys {
+				count += len(devicesByHintKey[key])
+			}
+
+			if count >= size && (bestKeys == nil || count < bestCount) {
+				bestKeys = keys
+				bestCount = count
+			}
+		}
+
+		if bestKeys != nil {
+			return t.collect(bestKeys, devicesByHintKey, required, size)
+		}
+	}
+
+	return NewDeviceSet()
+}
+
+// collect picks devices from the given keys, starting from required devices.
+// It prefers devices closest to already collected devices in the tree, and then cards with more remaining devices.
+func (t *treeNpuAllocator) collect(keys []TopologyHintKey, devicesByHintKey map[TopologyHintKey][]Device, required DeviceSet, size int) DeviceSet {
+	collected := NewDeviceSet(required.Devices()...)
+	remaining := make(map[TopologyHintKey][]Device, len(keys))
+	collectedHintKeys := make(map[TopologyHintKey]bool)
+	for _, key := range keys {
+		for _, device := range devicesByHintKey[key] {
+			if collected.Contains(device) {
+				collectedHintKeys[key] = true
+				continue
+			}
+
+			remaining[key] = append(remaining[key], device)
+		}
+	}
+
+	for collected.Len() < size {
+		var bestKey TopologyHintKey
+		bestLevel := -1
+		for _, key := range keys {
+			if len(remaining[key]) == 0 {
+				continue
+			}
+
+			level := 0
+			for collectedKey := range collectedHintKeys {
+				level = max(level, t.commonLevel(key, collectedKey))
+			}
+
+			if level > bestLevel || (level == bestLevel && len(remaining[key]) > len(remaining[bestKey])) {
+				bestKey = key
+				bestLevel = level
+			}
+		}
+
+		collected.Insert(remaining[bestKey][0])
+		remaining[bestKey] = remaining[bestKey][1:]
+		collectedHintKeys[bestKey] = true
+	}
+
+	return collected
+}
+
+func containsAllHintKeys(keys []TopologyHintKey, targets []TopologyHintKey) bool {
+	for _, target := range targets {
+		found := false
+		for _, key := range keys {
+			if key == target {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
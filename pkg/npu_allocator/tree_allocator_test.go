@@ -0,0 +1,134 @@
+package npu_allocator
+
+import (
+	"testing"
+
+	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
+	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildStaticTopologyTreePathsForTwoSocketBalancedConfig() TopologyTreePaths {
+	return TopologyTreePaths{
+		"0": {"numa-0", "hostbridge-0", "switch-0"},
+		"1": {"numa-0", "hostbridge-0", "switch-0"},
+		"2": {"numa-0", "hostbridge-2", "switch-2"},
+		"3": {"numa-0", "hostbridge-2", "switch-2"},
+		"4": {"numa-1", "hostbridge-4", "switch-4"},
+		"5": {"numa-1", "hostbridge-4", "switch-4"},
+		"6": {"numa-1", "hostbridge-6", "switch-6"},
+		"7": {"numa-1", "hostbridge-6", "switch-6"},
+	}
+}
+
+func TestTreeNpuAllocator(t *testing.T) {
+	busyCard := generateSameBoardMockDeviceSet(0, 2, "0")
+	idleCard := generateSameBoardMockDeviceSet(4, 4, "4")
+
+	tests := []struct {
+		description string
+		available   DeviceSet
+		required    DeviceSet
+		request     int
+		expected    DeviceSet
+	}{
+		{
+			description: "request two devices from the same switch",
+			available:   buildMockDeviceSet(0, 7),
+			required:    NewDeviceSet(),
+			request:     2,
+			expected:    NewDeviceSet(buildMockDevice(0), buildMockDevice(1)),
+		},
+		{
+			description: "intact switch is preferred over fragmented one",
+			available:   NewDeviceSet(buildMockDevice(0), buildMockDevice(2), buildMockDevice(3), buildMockDevice(4)),
+			required:    NewDeviceSet(),
+			request:     2,
+			expected:    NewDeviceSet(buildMockDevice(2), buildMockDevice(3)),
+		},
+		{
+			description: "the lowest subtree fitting the request is the numa node",
+			available:   NewDeviceSet(buildMockDevice(0), buildMockDevice(2), buildMockDevice(4), buildMockDevice(5), buildMockDevice(6), buildMockDevice(7)),
+			required:    NewDeviceSet(),
+			request:     3,
+			expected:    NewDeviceSet(buildMockDevice(4), buildMockDevice(5), buildMockDevice(6)),
+		},
+		{
+			description: "subtree must contain required device",
+			available:   buildMockDeviceSet(0, 7),
+			required:    NewDeviceSet(buildMockDevice(3)),
+			request:     2,
+			expected:    NewDeviceSet(buildMockDevice(2), buildMockDevice(3)),
+		},
+		{
+			description: "required devices across numa nodes span the machine",
+			available:   buildMockDeviceSet(0, 7),
+			required:    NewDeviceSet(buildMockDevice(0), buildMockDevice(4)),
+			request:     3,
+			expected:    NewDeviceSet(buildMockDevice(0), buildMockDevice(1), buildMockDevice(4)),
+		},
+		{
+			description: "single card fitting the request is preferred over several cards",
+			available:   busyCard.Union(idleCard.Devices()...).Union(buildMockDevice(1)),
+			required:    NewDeviceSet(),
+			request:     3,
+			expected:    NewDeviceSet(idleCard.Devices()[:3]...),
+		},
+		{
+			description: "request more than available devices",
+			available:   buildMockDeviceSet(0, 3),
+			required:    NewDeviceSet(),
+			request:     5,
+			expected:    NewDeviceSet(),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			sut, err := NewMockTreeNpuAllocator(buildStaticTopologyTreePathsForTwoSocketBalancedConfig())
+			assert.NoError(t, err)
+
+			actual := sut.Allocate(tc.available, tc.required, tc.request)
+
+			assert.Truef(t, tc.expected.Equal(actual.Devices()...), "expected %v but got %v", tc.expected.Devices(), actual.Devices())
+		})
+	}
+}
+
+func TestNewMockTreeNpuAllocatorWithInconsistentDepth(t *testing.T) {
+	_, err := NewMockTreeNpuAllocator(TopologyTreePaths{
+		"0": {"numa-0", "hostbridge-0"},
+		"1": {"numa-0"},
+	})
+	assert.Error(t, err)
+}
+
+func TestTreeNpuAllocatorWithSmiDevicesAndSysfs(t *testing.T) {
+	smiDevices := smi.GetStaticMockDevices(smi.ArchRngd)
+
+	furiosaDevices, err := furiosa_device.NewFuriosaDevices(smiDevices, nil, furiosa_device.NonePolicy)
+	assert.NoError(t, err)
+
+	available := NewDeviceSet()
+	for _, furiosaDevice := range furiosaDevices {
+		available.Insert(NewDevice(furiosaDevice))
+	}
+
+	fromSmi, err := NewTreeNpuAllocator(smiDevices)
+	assert.NoError(t, err)
+
+	root, bdfs := buildMockSysfsForTwoSocketBalancedConfig(t)
+	fromSysfs, err := NewTreeNpuAllocatorFromSysfs(root, bdfs)
+	assert.NoError(t, err)
+
+	for _, sut := range []NpuAllocator{fromSmi, fromSysfs} {
+		actual := sut.Allocate(available, NewDeviceSet(available.Devices()[2]), 2)
+
+		hintKeys := make([]TopologyHintKey, 0, actual.Len())
+		for _, device := range actual.Devices() {
+			hintKeys = append(hintKeys, device.TopologyHintKey())
+		}
+
+		assert.Equal(t, []TopologyHintKey{"51", "57"}, hintKeys)
+	}
+}
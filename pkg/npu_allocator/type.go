@@ -1,6 +1,9 @@
 package npu_allocator
 
 import (
+	"iter"
+	"sort"
+
 	"github.com/furiosa-ai/furiosa-smi-go/pkg/smi"
 	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/furiosa_device"
 	"github.com/furiosa-ai/libfuriosa-kubernetes/pkg/util"
@@ -55,8 +58,13 @@ type DeviceSet interface {
 	Equal(target ...Device) bool
 	Difference(target ...Device) DeviceSet
 	Union(target ...Device) DeviceSet
+	Intersection(target ...Device) DeviceSet
+	SymmetricDifference(target ...Device) DeviceSet
+	Filter(predicate func(device Device) bool) DeviceSet
+	GroupByTopologyHintKey() map[TopologyHintKey]DeviceSet
 	Insert(target ...Device)
 	Devices() []Device
+	All() iter.Seq[Device]
 	Len() int
 }
 
@@ -130,16 +138,11 @@ func (source *deviceSet) Difference(target ...Device) DeviceSet {
 		return nil
 	}
 
-	targetDeviceSet := NewDeviceSet(target...)
+	targetKeys := newDeviceKeySet(target...)
 
-	difference := NewDeviceSet()
-	for _, sourceDevice := range source.Devices() {
-		if !targetDeviceSet.Contains(sourceDevice) {
-			difference.Insert(sourceDevice)
-		}
-	}
-
-	return difference
+	return source.Filter(func(device Device) bool {
+		return !targetKeys.has(device)
+	})
 }
 
 // Union returns new DeviceSet containing elements of source and target DeviceSets
@@ -182,7 +185,106 @@ func (source *deviceSet) Len() int {
 	return source.btreeSet.Len()
 }
 
-// TODO(@bg): impl Intersection()
+// Intersection returns a subset of the source DeviceSet that is also in the target DeviceSet.
+func (source *deviceSet) Intersection(target ...Device) DeviceSet {
+	if source == nil || source.btreeSet == nil {
+		return nil
+	}
+
+	intersection := NewDeviceSet()
+	for _, targetDevice := range target {
+		if source.btreeSet.Has(targetDevice) {
+			intersection.Insert(targetDevice)
+		}
+	}
+
+	return intersection
+}
+
+// SymmetricDifference returns new DeviceSet containing elements in either the source or the target DeviceSet, but not in both.
+func (source *deviceSet) SymmetricDifference(target ...Device) DeviceSet {
+	if source == nil || source.btreeSet == nil {
+		return nil
+	}
+
+	symmetricDifference := source.Difference(target...)
+	for _, targetDevice := range target {
+		if !source.btreeSet.Has(targetDevice) {
+			symmetricDifference.Insert(targetDevice)
+		}
+	}
+
+	return symmetricDifference
+}
+
+// Filter returns a subset of the source DeviceSet satisfying the predicate.
+func (source *deviceSet) Filter(predicate func(device Device) bool) DeviceSet {
+	if source == nil || source.btreeSet == nil {
+		return nil
+	}
+
+	filtered := NewDeviceSet()
+	for device := range source.btreeSet.All() {
+		if predicate(device) {
+			filtered.Insert(device)
+		}
+	}
+
+	return filtered
+}
+
+// GroupByTopologyHintKey splits the source DeviceSet by TopologyHintKey of devices.
+func (source *deviceSet) GroupByTopologyHintKey() map[TopologyHintKey]DeviceSet {
+	groups := make(map[TopologyHintKey]DeviceSet)
+	if source == nil || source.btreeSet == nil {
+		return groups
+	}
+
+	for device := range source.btreeSet.All() {
+		hintKey := device.TopologyHintKey()
+		if _, ok := groups[hintKey]; !ok {
+			groups[hintKey] = NewDeviceSet()
+		}
+
+		groups[hintKey].Insert(device)
+	}
+
+	return groups
+}
+
+// All returns an iterator over devices in the same order as Devices, without copying them into a slice.
+func (source *deviceSet) All() iter.Seq[Device] {
+	return func(yield func(Device) bool) {
+		if source == nil || source.btreeSet == nil {
+			return
+		}
+
+		source.btreeSet.All()(yield)
+	}
+}
+
+// deviceKey identifies Device in the same way as the less function of DeviceSet.
+type deviceKey struct {
+	index int
+	id    string
+}
+
+type deviceKeySet map[deviceKey]struct{}
+
+func newDeviceKeySet(devices ...Device) deviceKeySet {
+	keys := make(deviceKeySet, len(devices))
+	for _, device := range devices {
+		keys[deviceKey{index: device.Index(), id: device.ID()}] = struct{}{}
+	}
+
+	return keys
+}
+
+func (s deviceKeySet) has(device Device) bool {
+	_, ok := s[deviceKey{index: device.Index(), id: device.ID()}]
+
+	return ok
+}
 
 // TopologyHintProvider takes two devices as argument return topology hint.
 // The hint would be score, distance, preference of two devices.
@@ -197,49 +299,53 @@ type TopologyHintMatrix map[TopologyHintKey]map[TopologyHintKey]uint
 // TopologyScoreCalculator calculates sum of score of given topologyHintKeys based on smi.Device smi.LinkType.
 type TopologyScoreCalculator func(keys []TopologyHintKey) uint
 
-// NewTopologyHintMatrix generates TopologyHintMatrix using list of smi.Device.
+// NewTopologyHintMatrix generates TopologyHintMatrix using list of smi.Device with DefaultTopologyScoringPolicy.
 func NewTopologyHintMatrix(smiDevices []smi.Device) (TopologyHintMatrix, error) {
-	topologyHintMatrix := make(TopologyHintMatrix)
-	deviceToDeviceInfo := make(map[smi.Device]smi.DeviceInfo)
+	return NewTopologyHintMatrixWithScoringPolicy(smiDevices, DefaultTopologyScoringPolicy())
+}
+
+// NewTopologyHintMatrixWithScoringPolicy generates TopologyHintMatrix using list of smi.Device with the given TopologyScoringPolicy.
+func NewTopologyHintMatrixWithScoringPolicy(smiDevices []smi.Device, scoringPolicy TopologyScoringPolicy) (TopologyHintMatrix, error) {
+	type keyedDevice struct {
+		device     smi.Device
+		deviceInfo smi.DeviceInfo
+		key        TopologyHintKey
+	}
 
+	keyedDevices := make([]keyedDevice, 0, len(smiDevices))
 	for _, device := range smiDevices {
 		deviceInfo, err := device.DeviceInfo()
 		if err != nil {
 			return nil, err
 		}
 
-		deviceToDeviceInfo[device] = deviceInfo
-	}
-
-	for device1, deviceInfo1 := range deviceToDeviceInfo {
-		for device2, deviceInfo2 := range deviceToDeviceInfo {
-			linkType, err := device1.DeviceToDeviceLinkType(device2)
-			if err != nil {
-				return nil, err
-			}
+		pciBusID, err := util.ParseBusIDFromBDF(deviceInfo.BDF())
+		if err != nil {
+			return nil, err
+		}
 
-			score := uint(linkType)
+		keyedDevices = append(keyedDevices, keyedDevice{device: device, deviceInfo: deviceInfo, key: TopologyHintKey(pciBusID)})
+	}
 
-			pciBusID1, err := util.ParseBusIDFromBDF(deviceInfo1.BDF())
-			if err != nil {
-				return nil, err
-			}
+	// Each unordered pair is scored once with the device of the smaller key first, so the matrix is deterministic
+	// even if the scoring policy is asymmetric.
+	sort.SliceStable(keyedDevices, func(i, j int) bool {
+		return keyedDevices[i].key < keyedDevices[j].key
+	})
 
-			pciBusID2, err := util.ParseBusIDFromBDF(deviceInfo2.BDF())
+	topologyHintMatrix := make(TopologyHintMatrix)
+	for i, d1 := range keyedDevices {
+		for _, d2 := range keyedDevices[i:] {
+			score, err := scoringPolicy.score(d1.device, d1.deviceInfo, d2.device, d2.deviceInfo)
 			if err != nil {
 				return nil, err
 			}
 
-			key1, key2 := TopologyHintKey(pciBusID1), TopologyHintKey(pciBusID2)
-			if key1 > key2 {
-				key1, key2 = key2, key1
-			}
-
-			if _, ok := topologyHintMatrix[key1]; !ok {
-				topologyHintMatrix[key1] = make(map[TopologyHintKey]uint)
+			if _, ok := topologyHintMatrix[d1.key]; !ok {
+				topologyHintMatrix[d1.key] = make(map[TopologyHintKey]uint)
 			}
 
-			topologyHintMatrix[key1][key2] = score
+			topologyHintMatrix[d1.key][d2.key] = score
 		}
 	}
 
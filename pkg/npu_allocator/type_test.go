@@ -407,3 +407,115 @@ func TestDeviceSetUnion(t *testing.T) {
 		})
 	}
 }
+
+func TestDeviceSetIntersection(t *testing.T) {
+	tests := []struct {
+		description string
+		source      DeviceSet
+		target      DeviceSet
+		expected    DeviceSet
+	}{
+		{
+			description: "Intersection empty DeviceSets",
+			source:      NewDeviceSet(),
+			target:      NewDeviceSet(),
+			expected:    NewDeviceSet(),
+		},
+		{
+			description: "Intersection source and target without intersection",
+			source:      buildMockDeviceSet(0, 1),
+			target:      buildMockDeviceSet(2, 3),
+			expected:    NewDeviceSet(),
+		},
+		{
+			description: "Intersection source and target with intersection",
+			source:      buildMockDeviceSet(0, 3),
+			target:      buildMockDeviceSet(2, 5),
+			expected:    buildMockDeviceSet(2, 3),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			actual := tc.source.Intersection(tc.target.Devices()...).Devices()
+			assert.Equal(t, tc.expected.Devices(), actual)
+		})
+	}
+}
+
+func TestDeviceSetSymmetricDifference(t *testing.T) {
+	tests := []struct {
+		description string
+		source      DeviceSet
+		target      DeviceSet
+		expected    DeviceSet
+	}{
+		{
+			description: "SymmetricDifference empty DeviceSets",
+			source:      NewDeviceSet(),
+			target:      NewDeviceSet(),
+			expected:    NewDeviceSet(),
+		},
+		{
+			description: "SymmetricDifference empty source",
+			source:      NewDeviceSet(),
+			target:      buildMockDeviceSet(0, 1),
+			expected:    buildMockDeviceSet(0, 1),
+		},
+		{
+			description: "SymmetricDifference source and target with intersection",
+			source:      buildMockDeviceSet(0, 3),
+			target:      buildMockDeviceSet(2, 5),
+			expected:    NewDeviceSet(buildMockDevice(0), buildMockDevice(1), buildMockDevice(4), buildMockDevice(5)),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			actual := tc.source.SymmetricDifference(tc.target.Devices()...).Devices()
+			assert.Equal(t, tc.expected.Devices(), actual)
+		})
+	}
+}
+
+func TestDeviceSetFilter(t *testing.T) {
+	source := buildMockDeviceSet(0, 7)
+
+	actual := source.Filter(func(device Device) bool {
+		return device.Index()%2 == 0
+	})
+
+	assert.Equal(t, NewDeviceSet(buildMockDevice(0), buildMockDevice(2), buildMockDevice(4), buildMockDevice(6)).Devices(), actual.Devices())
+	assert.Equal(t, 8, source.Len())
+}
+
+func TestDeviceSetGroupByTopologyHintKey(t *testing.T) {
+	first := generateSameBoardMockDeviceSet(0, 2, "0")
+	second := generateSameBoardMockDeviceSet(1, 3, "1")
+
+	actual := first.Union(second.Devices()...).GroupByTopologyHintKey()
+
+	assert.Len(t, actual, 2)
+	assert.Equal(t, first.Devices(), actual["0"].Devices())
+	assert.Equal(t, second.Devices(), actual["1"].Devices())
+	assert.Empty(t, NewDeviceSet().GroupByTopologyHintKey())
+}
+
+func TestDeviceSetAll(t *testing.T) {
+	source := NewDeviceSet(buildMockDevice(3), buildMockDevice(1), buildMockDevice(2), buildMockDevice(0))
+
+	actual := make([]Device, 0)
+	for device := range source.All() {
+		actual = append(actual, device)
+	}
+
+	assert.Equal(t, source.Devices(), actual)
+
+	count := 0
+	for range source.All() {
+		count++
+		break
+	}
+
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,57 @@
+package sysfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// CreateMockPCIDevice creates a synthetic sysfs tree of a PCI device under the root for test purpose.
+// hierarchy is a list of path components from the root bus to the device, such as
+// []string{"pci0000:00", "0000:00:01.0", "0000:01:00.0"}, and the last component is used as BDF of the device.
+// numa_node and local_cpulist files are not created if numaNode is negative or localCPUList is empty.
+func CreateMockPCIDevice(root string, hierarchy []string, numaNode int, localCPUList string) error {
+	if len(hierarchy) < 2 {
+		return fmt.Errorf("hierarchy must contain root bus and device, got %v", hierarchy)
+	}
+
+	devicePath := filepath.Join(append([]string{root, devicesDir}, hierarchy...)...)
+	if err := os.MkdirAll(devicePath, 0755); err != nil {
+		return err
+	}
+
+	if numaNode >= 0 {
+		if err := os.WriteFile(filepath.Join(devicePath, numaNodeFile), []byte(strconv.Itoa(numaNode)+"\n"), 0644); err != nil {
+			return err
+		}
+	}
+
+	if localCPUList != "" {
+		if err := os.WriteFile(filepath.Join(devicePath, localCPUListFile), []byte(localCPUList+"\n"), 0644); err != nil {
+			return err
+		}
+	}
+
+	linkDir := filepath.Join(root, pciDevicesDir)
+	if err := os.MkdirAll(linkDir, 0755); err != nil {
+		return err
+	}
+
+	target, err := filepath.Rel(linkDir, devicePath)
+	if err != nil {
+		return err
+	}
+
+	return os.Symlink(target, filepath.Join(linkDir, hierarchy[len(hierarchy)-1]))
+}
+
+// CreateMockNUMANode creates a synthetic sysfs entry of a NUMA node with its cpulist under the root for test purpose.
+func CreateMockNUMANode(root string, node int, cpuList string) error {
+	nodePath := filepath.Join(root, nodeDir, fmt.Sprintf("node%d", node))
+	if err := os.MkdirAll(nodePath, 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(nodePath, cpuListFile), []byte(cpuList+"\n"), 0644)
+}
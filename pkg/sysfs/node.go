@@ -0,0 +1,29 @@
+package sysfs
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+const (
+	nodeDir     = "devices/system/node"
+	cpuListFile = "cpulist"
+)
+
+// ReadNUMANodeCPUList reads the content of `<root>/devices/system/node/node<N>/cpulist` such as "0-15,32-47".
+func ReadNUMANodeCPUList(root string, node int) (string, error) {
+	if node < 0 {
+		return "", fmt.Errorf("invalid numa node %d", node)
+	}
+
+	cpuList, err := readOptionalFile(filepath.Join(root, nodeDir, fmt.Sprintf("node%d", node), cpuListFile))
+	if err != nil {
+		return "", err
+	}
+
+	if cpuList == "" {
+		return "", fmt.Errorf("cpu list of numa node %d is not found under %s", node, root)
+	}
+
+	return cpuList, nil
+}
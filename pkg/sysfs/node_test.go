@@ -0,0 +1,22 @@
+package sysfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadNUMANodeCPUList(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, CreateMockNUMANode(root, 1, "16-31"))
+
+	actual, err := ReadNUMANodeCPUList(root, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "16-31", actual)
+
+	_, err = ReadNUMANodeCPUList(root, 0)
+	assert.Error(t, err)
+
+	_, err = ReadNUMANodeCPUList(root, -1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,146 @@
+package sysfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultRoot = "/sys"
+
+	pciDevicesDir    = "bus/pci/devices"
+	devicesDir       = "devices"
+	numaNodeFile     = "numa_node"
+	localCPUListFile = "local_cpulist"
+	rootBusPrefix    = "pci"
+	unknownNUMANode  = -1
+)
+
+// PCIDevice describes a PCI device and its location in the PCI hierarchy read from sysfs.
+type PCIDevice struct {
+	BDF string
+
+	// NUMANode is -1 if the platform does not report NUMA affinity.
+	NUMANode int
+
+	// RootBus is the root bus of the host bridge(root complex) such as "pci0000:00".
+	RootBus string
+
+	// UpstreamBridges are BDFs of bridges from the root port to the closest upstream bridge of the device.
+	UpstreamBridges []string
+
+	// LocalCPUList is the content of `local_cpulist` such as "0-15,32-47". It is empty if not reported.
+	LocalCPUList string
+}
+
+// RootPort returns BDF of the root port the device is attached to, or empty string if the device is attached to the root bus directly.
+func (d *PCIDevice) RootPort() string {
+	if len(d.UpstreamBridges) == 0 {
+		return ""
+	}
+
+	return d.UpstreamBridges[0]
+}
+
+// UpstreamBridge returns BDF of the closest upstream bridge.
+func (d *PCIDevice) UpstreamBridge() string {
+	if len(d.UpstreamBridges) == 0 {
+		return ""
+	}
+
+	return d.UpstreamBridges[len(d.UpstreamBridges)-1]
+}
+
+// Switch returns BDF of the upstream port of the closest PCIe switch.
+// A device under a switch has root port, switch upstream port and switch downstream port as upstream bridges.
+// It returns empty string if the device is attached to the root port directly.
+func (d *PCIDevice) Switch() string {
+	if len(d.UpstreamBridges) < 3 {
+		return ""
+	}
+
+	return d.UpstreamBridges[len(d.UpstreamBridges)-2]
+}
+
+// ReadPCIDevice reads the PCI device with the given BDF from sysfs mounted under the root.
+// The hierarchy is resolved from the symlink `<root>/bus/pci/devices/<bdf>`, which points to
+// `<root>/devices/pci<domain>:<bus>/<root port>/.../<bdf>`.
+func ReadPCIDevice(root string, bdf string) (*PCIDevice, error) {
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedPath, err := filepath.EvalSymlinks(filepath.Join(resolvedRoot, pciDevicesDir, bdf))
+	if err != nil {
+		return nil, err
+	}
+
+	relPath, err := filepath.Rel(filepath.Join(resolvedRoot, devicesDir), resolvedPath)
+	if err != nil {
+		return nil, err
+	}
+
+	components := strings.Split(filepath.ToSlash(relPath), "/")
+	if len(components) < 2 || !strings.HasPrefix(components[0], rootBusPrefix) || components[len(components)-1] != bdf {
+		return nil, fmt.Errorf("unexpected sysfs path %s for pci device %s", resolvedPath, bdf)
+	}
+
+	numaNode, err := readNUMANode(filepath.Join(resolvedPath, numaNodeFile))
+	if err != nil {
+		return nil, err
+	}
+
+	localCPUList, err := readOptionalFile(filepath.Join(resolvedPath, localCPUListFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return &PCIDevice{
+		BDF:             bdf,
+		NUMANode:        numaNode,
+		RootBus:         components[0],
+		UpstreamBridges: components[1 : len(components)-1],
+		LocalCPUList:    localCPUList,
+	}, nil
+}
+
+// readOptionalFile returns trimmed content of the file, or empty string if the file does not exist.
+func readOptionalFile(path string) (string, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(raw)), nil
+}
+
+func readNUMANode(path string) (int, error) {
+	raw, err := readOptionalFile(path)
+	if err != nil {
+		return unknownNUMANode, err
+	}
+
+	if raw == "" {
+		return unknownNUMANode, nil
+	}
+
+	numaNode, err := strconv.Atoi(raw)
+	if err != nil {
+		return unknownNUMANode, fmt.Errorf("couldn't parse numa node from %s: %w", path, err)
+	}
+
+	if numaNode < 0 {
+		return unknownNUMANode, nil
+	}
+
+	return numaNode, nil
+}
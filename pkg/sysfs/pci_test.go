@@ -0,0 +1,88 @@
+package sysfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadPCIDevice(t *testing.T) {
+	tests := []struct {
+		description  string
+		hierarchy    []string
+		numaNode     int
+		localCPUList string
+		expected     *PCIDevice
+		expectedSw   string
+		expectedRP   string
+		expectedUp   string
+	}{
+		{
+			description:  "device under pcie switch",
+			hierarchy:    []string{"pci0000:16", "0000:16:01.0", "0000:17:00.0", "0000:18:00.0", "0000:27:00.0"},
+			numaNode:     0,
+			localCPUList: "0-15",
+			expected: &PCIDevice{
+				BDF:             "0000:27:00.0",
+				NUMANode:        0,
+				RootBus:         "pci0000:16",
+				UpstreamBridges: []string{"0000:16:01.0", "0000:17:00.0", "0000:18:00.0"},
+				LocalCPUList:    "0-15",
+			},
+			expectedSw: "0000:17:00.0",
+			expectedRP: "0000:16:01.0",
+			expectedUp: "0000:18:00.0",
+		},
+		{
+			description:  "device under root port without numa affinity",
+			hierarchy:    []string{"pci0000:80", "0000:80:01.0", "0000:81:00.0"},
+			numaNode:     -1,
+			localCPUList: "",
+			expected: &PCIDevice{
+				BDF:             "0000:81:00.0",
+				NUMANode:        -1,
+				RootBus:         "pci0000:80",
+				UpstreamBridges: []string{"0000:80:01.0"},
+				LocalCPUList:    "",
+			},
+			expectedSw: "",
+			expectedRP: "0000:80:01.0",
+			expectedUp: "0000:80:01.0",
+		},
+		{
+			description:  "device attached to root bus",
+			hierarchy:    []string{"pci0000:00", "0000:00:05.0"},
+			numaNode:     1,
+			localCPUList: "16-31",
+			expected: &PCIDevice{
+				BDF:             "0000:00:05.0",
+				NUMANode:        1,
+				RootBus:         "pci0000:00",
+				UpstreamBridges: []string{},
+				LocalCPUList:    "16-31",
+			},
+			expectedSw: "",
+			expectedRP: "",
+			expectedUp: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			root := t.TempDir()
+			assert.NoError(t, CreateMockPCIDevice(root, tc.hierarchy, tc.numaNode, tc.localCPUList))
+
+			actual, err := ReadPCIDevice(root, tc.hierarchy[len(tc.hierarchy)-1])
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+			assert.Equal(t, tc.expectedSw, actual.Switch())
+			assert.Equal(t, tc.expectedRP, actual.RootPort())
+			assert.Equal(t, tc.expectedUp, actual.UpstreamBridge())
+		})
+	}
+}
+
+func TestReadPCIDeviceNotFound(t *testing.T) {
+	_, err := ReadPCIDevice(t.TempDir(), "0000:27:00.0")
+	assert.Error(t, err)
+}
@@ -1,6 +1,8 @@
 package util
 
 import (
+	"iter"
+
 	"github.com/google/btree"
 )
 
@@ -51,6 +53,79 @@ func (m *BtreeMap[K, V]) Insert(key K, value V) {
 	m.tree.ReplaceOrInsert(BtreeMapItem[K, V]{Key: key, Value: value})
 }
 
+// Delete removes the key and returns its value if it exists.
+func (m *BtreeMap[K, V]) Delete(key K) (V, bool) {
+	item, exists := m.tree.Delete(BtreeMapItem[K, V]{Key: key})
+
+	return item.Value, exists
+}
+
+func (m *BtreeMap[K, V]) Len() int {
+	return m.tree.Len()
+}
+
+// Min returns the smallest key and its value, or false if the map is empty.
+func (m *BtreeMap[K, V]) Min() (K, V, bool) {
+	item, exists := m.tree.Min()
+
+	return item.Key, item.Value, exists
+}
+
+// Max returns the largest key and its value, or false if the map is empty.
+func (m *BtreeMap[K, V]) Max() (K, V, bool) {
+	item, exists := m.tree.Max()
+
+	return item.Key, item.Value, exists
+}
+
+// Clone returns a copy of the map. The copy is lazy and copy-on-write, so both maps can be modified independently.
+func (m *BtreeMap[K, V]) Clone() *BtreeMap[K, V] {
+	return &BtreeMap[K, V]{tree: m.tree.Clone()}
+}
+
+// All returns an iterator over key-value pairs in ascending order of keys.
+func (m *BtreeMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.tree.Ascend(func(item BtreeMapItem[K, V]) bool {
+			return yield(item.Key, item.Value)
+		})
+	}
+}
+
+// Items returns an iterator over items in ascending order of keys.
+func (m *BtreeMap[K, V]) Items() iter.Seq[BtreeMapItem[K, V]] {
+	return func(yield func(BtreeMapItem[K, V]) bool) {
+		m.tree.Ascend(btree.ItemIteratorG[BtreeMapItem[K, V]](yield))
+	}
+}
+
+// Values returns an iterator over values in ascending order of keys.
+func (m *BtreeMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.tree.Ascend(func(item BtreeMapItem[K, V]) bool {
+			return yield(item.Value)
+		})
+	}
+}
+
+// AscendRange returns an iterator over key-value pairs in the range [greaterOrEqual, lessThan), in ascending order.
+func (m *BtreeMap[K, V]) AscendRange(greaterOrEqual, lessThan K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.tree.AscendRange(BtreeMapItem[K, V]{Key: greaterOrEqual}, BtreeMapItem[K, V]{Key: lessThan}, func(item BtreeMapItem[K, V]) bool {
+			return yield(item.Key, item.Value)
+		})
+	}
+}
+
+// DescendRange returns an iterator over key-value pairs in the range (greaterThan, lessOrEqual], in descending order.
+func (m *BtreeMap[K, V]) DescendRange(lessOrEqual, greaterThan K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.tree.DescendRange(BtreeMapItem[K, V]{Key: lessOrEqual}, BtreeMapItem[K, V]{Key: greaterThan}, func(item BtreeMapItem[K, V]) bool {
+			return yield(item.Key, item.Value)
+		})
+	}
+}
+
 type BtreeSet[T any] struct {
 	tree *btree.BTreeG[T]
 }
@@ -87,3 +162,46 @@ func (s *BtreeSet[T]) Items() []T {
 func (s *BtreeSet[T]) Insert(item T) {
 	s.tree.ReplaceOrInsert(item)
 }
+
+// Delete removes the item and returns whether it existed.
+func (s *BtreeSet[T]) Delete(item T) bool {
+	_, exists := s.tree.Delete(item)
+
+	return exists
+}
+
+// Min returns the smallest item, or false if the set is empty.
+func (s *BtreeSet[T]) Min() (T, bool) {
+	return s.tree.Min()
+}
+
+// Max returns the largest item, or false if the set is empty.
+func (s *BtreeSet[T]) Max() (T, bool) {
+	return s.tree.Max()
+}
+
+// Clone returns a copy of the set. The copy is lazy and copy-on-write, so both sets can be modified independently.
+func (s *BtreeSet[T]) Clone() *BtreeSet[T] {
+	return &BtreeSet[T]{tree: s.tree.Clone()}
+}
+
+// All returns an iterator over items in ascending order without copying them into a slice.
+func (s *BtreeSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.tree.Ascend(btree.ItemIteratorG[T](yield))
+	}
+}
+
+// AscendRange returns an iterator over items in the range [greaterOrEqual, lessThan), in ascending order.
+func (s *BtreeSet[T]) AscendRange(greaterOrEqual, lessThan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.tree.AscendRange(greaterOrEqual, lessThan, btree.ItemIteratorG[T](yield))
+	}
+}
+
+// DescendRange returns an iterator over items in the range (greaterThan, lessOrEqual], in descending order.
+func (s *BtreeSet[T]) DescendRange(lessOrEqual, greaterThan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.tree.DescendRange(lessOrEqual, greaterThan, btree.ItemIteratorG[T](yield))
+	}
+}
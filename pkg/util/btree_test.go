@@ -66,3 +66,146 @@ func TestBtreeSet(t *testing.T) {
 		})
 	}
 }
+
+func TestBtreeIterators(t *testing.T) {
+	set := NewBtreeSetWithLessFunc[int](3, func(a, b int) bool {
+		return a < b
+	})
+	m := NewBtreeMapWithLessFunc[int, string](3, func(a, b BtreeMapItem[int, string]) bool {
+		return a.Key < b.Key
+	})
+
+	for _, number := range []int{2, 0, 1} {
+		set.Insert(number)
+		m.Insert(number, fmt.Sprint(number))
+	}
+
+	items := make([]int, 0)
+	for item := range set.All() {
+		items = append(items, item)
+	}
+
+	assert.Equal(t, []int{0, 1, 2}, items)
+
+	keys := make([]int, 0)
+	values := make([]string, 0)
+	for key, value := range m.All() {
+		if key == 2 {
+			break
+		}
+
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+
+	assert.Equal(t, []int{0, 1}, keys)
+	assert.Equal(t, []string{"0", "1"}, values)
+}
+
+func TestBtreeMapOperations(t *testing.T) {
+	sut := NewBtreeMapWithLessFunc[int, string](2, func(a, b BtreeMapItem[int, string]) bool {
+		return a.Key < b.Key
+	})
+
+	_, _, ok := sut.Min()
+	assert.False(t, ok)
+
+	for _, number := range []int{3, 0, 4, 1, 2} {
+		sut.Insert(number, fmt.Sprint(number))
+	}
+
+	clone := sut.Clone()
+
+	value, ok := sut.Delete(2)
+	assert.True(t, ok)
+	assert.Equal(t, "2", value)
+
+	_, ok = sut.Delete(2)
+	assert.False(t, ok)
+
+	assert.Equal(t, 4, sut.Len())
+	assert.Equal(t, 5, clone.Len())
+	assert.True(t, clone.Has(2))
+
+	minKey, minValue, ok := sut.Min()
+	assert.True(t, ok)
+	assert.Equal(t, 0, minKey)
+	assert.Equal(t, "0", minValue)
+
+	maxKey, maxValue, ok := sut.Max()
+	assert.True(t, ok)
+	assert.Equal(t, 4, maxKey)
+	assert.Equal(t, "4", maxValue)
+
+	ascending := make([]int, 0)
+	for key := range sut.AscendRange(1, 4) {
+		ascending = append(ascending, key)
+	}
+
+	assert.Equal(t, []int{1, 3}, ascending)
+
+	descending := make([]int, 0)
+	for key := range clone.DescendRange(3, 0) {
+		descending = append(descending, key)
+	}
+
+	assert.Equal(t, []int{3, 2, 1}, descending)
+
+	values := make([]string, 0)
+	for value := range sut.Values() {
+		values = append(values, value)
+	}
+
+	assert.Equal(t, []string{"0", "1", "3", "4"}, values)
+
+	items := make([]BtreeMapItem[int, string], 0)
+	for item := range sut.Items() {
+		items = append(items, item)
+	}
+
+	assert.Equal(t, BtreeMapItem[int, string]{Key: 0, Value: "0"}, items[0])
+	assert.Len(t, items, 4)
+}
+
+func TestBtreeSetOperations(t *testing.T) {
+	sut := NewBtreeSetWithLessFunc[int](2, func(a, b int) bool {
+		return a < b
+	})
+
+	_, ok := sut.Max()
+	assert.False(t, ok)
+
+	for _, number := range []int{3, 0, 4, 1, 2} {
+		sut.Insert(number)
+	}
+
+	clone := sut.Clone()
+	clone.Insert(5)
+
+	assert.True(t, sut.Delete(0))
+	assert.False(t, sut.Delete(0))
+	assert.Equal(t, []int{1, 2, 3, 4}, sut.Items())
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, clone.Items())
+
+	minItem, ok := sut.Min()
+	assert.True(t, ok)
+	assert.Equal(t, 1, minItem)
+
+	maxItem, ok := clone.Max()
+	assert.True(t, ok)
+	assert.Equal(t, 5, maxItem)
+
+	ascending := make([]int, 0)
+	for item := range clone.AscendRange(2, 5) {
+		ascending = append(ascending, item)
+	}
+
+	assert.Equal(t, []int{2, 3, 4}, ascending)
+
+	descending := make([]int, 0)
+	for item := range clone.DescendRange(5, 2) {
+		descending = append(descending, item)
+	}
+
+	assert.Equal(t, []int{5, 4, 3}, descending)
+}
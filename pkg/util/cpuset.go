@@ -0,0 +1,76 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseCPUSet parses cpuset string in Linux cpu list format such as "0-3,8,10-11", and returns sorted unique CPU ids.
+func ParseCPUSet(s string) ([]int, error) {
+	cpuSet := make(map[int]struct{})
+	for _, part := range strings.Split(strings.TrimSpace(s), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		start, end := part, part
+		if idx := strings.Index(part, "-"); idx != -1 {
+			start, end = part[:idx], part[idx+1:]
+		}
+
+		first, err := strconv.Atoi(start)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse cpuset %q: %w", s, err)
+		}
+
+		last, err := strconv.Atoi(end)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse cpuset %q: %w", s, err)
+		}
+
+		if first < 0 || first > last {
+			return nil, fmt.Errorf("couldn't parse cpuset %q: invalid range %q", s, part)
+		}
+
+		for cpu := first; cpu <= last; cpu++ {
+			cpuSet[cpu] = struct{}{}
+		}
+	}
+
+	cpus := make([]int, 0, len(cpuSet))
+	for cpu := range cpuSet {
+		cpus = append(cpus, cpu)
+	}
+
+	sort.Ints(cpus)
+
+	return cpus, nil
+}
+
+// FormatCPUSet formats CPU ids into cpuset string in Linux cpu list format, merging consecutive ids into ranges.
+func FormatCPUSet(cpus []int) string {
+	sorted := make([]int, len(cpus))
+	copy(sorted, cpus)
+	sort.Ints(sorted)
+
+	parts := make([]string, 0)
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] <= sorted[j]+1 {
+			j++
+		}
+
+		if sorted[i] == sorted[j] {
+			parts = append(parts, strconv.Itoa(sorted[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		}
+
+		i = j + 1
+	}
+
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,85 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCPUSet(t *testing.T) {
+	tests := []struct {
+		description string
+		input       string
+		expected    []int
+		expectError bool
+	}{
+		{
+			description: "ranges and single ids",
+			input:       "0-3,8,10-11\n",
+			expected:    []int{0, 1, 2, 3, 8, 10, 11},
+		},
+		{
+			description: "unsorted and duplicated ids",
+			input:       "5,1-2,2",
+			expected:    []int{1, 2, 5},
+		},
+		{
+			description: "empty string",
+			input:       "",
+			expected:    []int{},
+		},
+		{
+			description: "invalid range",
+			input:       "3-1",
+			expectError: true,
+		},
+		{
+			description: "invalid id",
+			input:       "a",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			actual, err := ParseCPUSet(tc.input)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestFormatCPUSet(t *testing.T) {
+	tests := []struct {
+		description string
+		input       []int
+		expected    string
+	}{
+		{
+			description: "consecutive ids are merged",
+			input:       []int{11, 0, 1, 2, 3, 8, 10},
+			expected:    "0-3,8,10-11",
+		},
+		{
+			description: "duplicated ids",
+			input:       []int{1, 1, 2},
+			expected:    "1-2",
+		},
+		{
+			description: "empty",
+			input:       nil,
+			expected:    "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FormatCPUSet(tc.input))
+		})
+	}
+}